@@ -0,0 +1,476 @@
+// Package client is the Go client of the meetings REST API.
+//
+//	c := client.New("http://localhost:8080")
+//	id, err := c.CreateMeeting(ctx, client.Meeting{Title: "standup", StartTime: start, EndTime: end})
+//	if errors.Is(err, client.ErrBadRequest) { ... }
+//
+//	meetings := c.IterateByParticipant("rishi@gmail.com", nil)
+//	for meetings.Next(ctx) {
+//		fmt.Println(meetings.Meeting().Title)
+//	}
+//	if err := meetings.Err(); err != nil { ... }
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dxmxnlord/golang-api/src/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// timeFormat is the format of the start and end query parameters of the api
+const timeFormat = "2006-01-02T15:04:05Z"
+
+// the types of the api, shared with the server through package model
+type (
+	Meeting     = model.Meeting
+	Participant = model.Participant
+	Override    = model.Override
+)
+
+// errors matched by errors.Is() for the status of an *Error
+var (
+	ErrBadRequest = errors.New("bad request")
+	ErrNotFound   = errors.New("not found")
+	ErrConflict   = errors.New("conflict")
+)
+
+/*
+	type Error - an error response of the api
+	@attributes:
+		StatusCode - http status of the response
+		Message - the error of the {"error": ...} body, the status text if the body has none
+*/
+
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (err *Error) Error() string {
+	return fmt.Sprintf("%d: %s", err.StatusCode, err.Message)
+}
+
+/*
+	function Error.Is()
+	@params:
+		target - error to compare
+	@return:
+		bool - true if target is the sentinel of the status: ErrBadRequest (400), ErrNotFound (404) or ErrConflict (409)
+*/
+
+func (err *Error) Is(target error) bool {
+	switch err.StatusCode {
+	case http.StatusBadRequest:
+		return target == ErrBadRequest
+	case http.StatusNotFound:
+		return target == ErrNotFound
+	case http.StatusConflict:
+		return target == ErrConflict
+	}
+	return false
+}
+
+/*
+	type Client - client of one api server
+	@attributes:
+		BaseURL - url of the server without the /api path, e.g. http://localhost:8080
+		HTTPClient - client sending the requests
+*/
+
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+/*
+	function New()
+	@params:
+		baseURL - url of the server without the /api path
+	@return:
+		*Client - client with a 30 second request timeout
+*/
+
+func New(baseURL string) *Client {
+	return &Client{BaseURL: strings.TrimSuffix(baseURL, "/"), HTTPClient: &http.Client{Timeout: 30 * time.Second}}
+}
+
+/*
+	function Client.CreateMeeting()
+	@params:
+		ctx - context of the request
+		meeting - meeting to create, without id
+	@return:
+		primitive.ObjectID - id of the created meeting
+		error - nil | *Error, ErrBadRequest for invalid and overlapping meetings | error
+*/
+
+func (client *Client) CreateMeeting(ctx context.Context, meeting Meeting) (primitive.ObjectID, error) {
+	body, err := json.Marshal(meeting)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+
+	var created struct {
+		InsertedID primitive.ObjectID `json:"InsertedID"`
+	}
+	err = client.do(ctx, "POST", "/api/meetings", nil, bytes.NewReader(body), &created, nil)
+	return created.InsertedID, err
+}
+
+/*
+	function Client.GetMeeting()
+	@params:
+		ctx - context of the request
+		id - id of the meeting
+	@return:
+		Meeting - the meeting
+		error - nil | *Error, ErrNotFound if there is no meeting with id | error
+*/
+
+func (client *Client) GetMeeting(ctx context.Context, id primitive.ObjectID) (Meeting, error) {
+	var meeting Meeting
+	err := client.do(ctx, "GET", "/api/meeting", url.Values{"id": {id.Hex()}}, nil, &meeting, nil)
+	return meeting, err
+}
+
+/*
+	function Client.SetRSVP()
+	@params:
+		ctx - context of the request
+		id - id of the meeting
+		email - email of the participant
+		rsvp - Yes, No, Maybe or Not Answered
+	@return:
+		Meeting - the meeting with the new rsvp
+		error - nil | *Error, ErrNotFound if there is no such meeting or participant,
+			ErrConflict if the participant is busy for a Yes | error
+*/
+
+func (client *Client) SetRSVP(ctx context.Context, id primitive.ObjectID, email, rsvp string) (Meeting, error) {
+	body, err := json.Marshal(Participant{Email: email, RSVP: rsvp})
+	if err != nil {
+		return Meeting{}, err
+	}
+
+	var meeting Meeting
+	err = client.do(ctx, "PUT", "/api/meetings/"+id.Hex()+"/rsvp", nil, bytes.NewReader(body), &meeting, nil)
+	return meeting, err
+}
+
+/*
+	function Client.MeetingICS()
+	@params:
+		ctx - context of the request
+		id - id of the meeting
+	@return:
+		[]byte - the meeting as iCalendar document
+		error - nil | *Error, ErrNotFound if there is no meeting with id | error
+*/
+
+func (client *Client) MeetingICS(ctx context.Context, id primitive.ObjectID) ([]byte, error) {
+	var calendar []byte
+	err := client.do(ctx, "GET", "/api/meeting", url.Values{"id": {id.Hex()}, "format": {"ics"}}, nil, &calendar, nil)
+	return calendar, err
+}
+
+/*
+	function Client.CalendarICS()
+	@params:
+		ctx - context of the request
+		email - email of the participant
+	@return:
+		[]byte - all meetings of the participant as iCalendar document, including the cancelled ones
+		error - nil | *Error | error
+*/
+
+func (client *Client) CalendarICS(ctx context.Context, email string) ([]byte, error) {
+	var calendar []byte
+	err := client.do(ctx, "GET", "/api/calendar", url.Values{"email": {email}}, nil, &calendar, nil)
+	return calendar, err
+}
+
+/*
+	type ListOptions - paging of a list request
+	@attributes:
+		PageSize - meetings per page, the page size of the server if 0, capped at its max page size
+		Cursor - NextCursor of the previous page, empty for the first page
+		IncludeCancelled - also list cancelled meetings
+*/
+
+type ListOptions struct {
+	PageSize         int
+	Cursor           string
+	IncludeCancelled bool
+}
+
+/*
+	type Page - one page of a list request
+	@attributes:
+		Meetings - meetings of the page, ordered by start time
+		Total - number of matching meetings
+		NextCursor - cursor of the following page, empty on the last page
+*/
+
+type Page struct {
+	Meetings   []Meeting
+	Total      int64
+	NextCursor string
+}
+
+/*
+	function Client.ListByRange()
+	@params:
+		ctx - context of the request
+		start, end - time range
+		options - paging, nil for the first page of the server page size
+	@description:
+		recurring meetings are expanded into their occurrences in the range
+	@return:
+		Page - one page of the meetings starting and ending between start and end
+		error - nil | *Error | error
+*/
+
+func (client *Client) ListByRange(ctx context.Context, start, end time.Time, options *ListOptions) (Page, error) {
+	query := url.Values{"start": {start.UTC().Format(timeFormat)}, "end": {end.UTC().Format(timeFormat)}}
+	return client.list(ctx, query, options)
+}
+
+/*
+	function Client.ListByParticipant()
+	@params:
+		ctx - context of the request
+		email - email of the participant
+		options - paging, nil for the first page of the server page size
+	@return:
+		Page - one page of the meetings of the participant
+		error - nil | *Error | error
+*/
+
+func (client *Client) ListByParticipant(ctx context.Context, email string, options *ListOptions) (Page, error) {
+	return client.list(ctx, url.Values{"email": {email}}, options)
+}
+
+/*
+	function Client.list()
+	@params:
+		ctx - context of the request
+		query - filters of the listing
+		options - paging, nil for the defaults
+	@description:
+		request the page with keyset paging and read the total and the next cursor from the page headers
+	@return:
+		Page - the page
+		error - nil | *Error | error
+*/
+
+func (client *Client) list(ctx context.Context, query url.Values, options *ListOptions) (Page, error) {
+	if options == nil {
+		options = &ListOptions{}
+	}
+	query.Set("cursor", options.Cursor)
+	if options.PageSize > 0 {
+		query.Set("page_size", strconv.Itoa(options.PageSize))
+	}
+	if options.IncludeCancelled {
+		query.Set("include_cancelled", "true")
+	}
+
+	var page Page
+	var header http.Header
+	if err := client.do(ctx, "GET", "/api/meetings", query, nil, &page.Meetings, &header); err != nil {
+		return Page{}, err
+	}
+	page.Total, _ = strconv.ParseInt(header.Get("X-Total-Count"), 10, 64)
+	page.NextCursor = header.Get("X-Next-Cursor")
+	return page, nil
+}
+
+/*
+	function Client.do()
+	@params:
+		ctx - context of the request
+		method - http method
+		path - path of the route
+		query - query parameters, nil for none
+		body - json body, nil for none
+		result - decodes the json body of a successful response into it, a *[]byte gets the body as is
+		header - gets the headers of the response, nil if they are not needed
+	@return:
+		error - nil | *Error for responses with a status of 400 or above | error
+*/
+
+func (client *Client) do(ctx context.Context, method, path string, query url.Values, body io.Reader, result interface{}, header *http.Header) error {
+	target := client.BaseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if _, raw := result.(*[]byte); !raw {
+		req.Header.Set("Accept", "application/json")
+	}
+
+	res, err := client.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 400 {
+		var failure struct {
+			Error string `json:"error"`
+		}
+		if json.NewDecoder(res.Body).Decode(&failure) != nil || failure.Error == "" {
+			failure.Error = http.StatusText(res.StatusCode)
+		}
+		return &Error{StatusCode: res.StatusCode, Message: failure.Error}
+	}
+	if header != nil {
+		*header = res.Header
+	}
+	if raw, ok := result.(*[]byte); ok {
+		*raw, err = io.ReadAll(res.Body)
+		return err
+	}
+	return json.NewDecoder(res.Body).Decode(result)
+}
+
+/*
+	type MeetingIterator - walks through all pages of a listing, like a mongoDb cursor
+	@attributes:
+		fetch - requests the page after a cursor
+		page - current page
+		index - position in the page, -1 before the first meeting
+		started - true once the first page was requested
+		err - error of the last request
+*/
+
+type MeetingIterator struct {
+	fetch   func(ctx context.Context, cursor string) (Page, error)
+	page    Page
+	index   int
+	started bool
+	err     error
+}
+
+/*
+	function Client.IterateByRange()
+	@params:
+		start, end - time range
+		options - page size and cancelled meetings, the cursor is the one to start after
+	@return:
+		*MeetingIterator - iterator over the meetings of Client.ListByRange() on all pages
+*/
+
+func (client *Client) IterateByRange(start, end time.Time, options *ListOptions) *MeetingIterator {
+	return newIterator(options, func(ctx context.Context, options *ListOptions) (Page, error) {
+		return client.ListByRange(ctx, start, end, options)
+	})
+}
+
+/*
+	function Client.IterateByParticipant()
+	@params:
+		email - email of the participant
+		options - page size and cancelled meetings, the cursor is the one to start after
+	@return:
+		*MeetingIterator - iterator over the meetings of Client.ListByParticipant() on all pages
+*/
+
+func (client *Client) IterateByParticipant(email string, options *ListOptions) *MeetingIterator {
+	return newIterator(options, func(ctx context.Context, options *ListOptions) (Page, error) {
+		return client.ListByParticipant(ctx, email, options)
+	})
+}
+
+func newIterator(options *ListOptions, list func(context.Context, *ListOptions) (Page, error)) *MeetingIterator {
+	first := ListOptions{}
+	if options != nil {
+		first = *options
+	}
+	return &MeetingIterator{
+		index: -1,
+		fetch: func(ctx context.Context, cursor string) (Page, error) {
+			pageOptions := first
+			if cursor != "" {
+				pageOptions.Cursor = cursor
+			}
+			return list(ctx, &pageOptions)
+		},
+	}
+}
+
+/*
+	function MeetingIterator.Next()
+	@params:
+		ctx - context of the page requests
+	@description:
+		move to the next meeting, requesting the following page once the current one is used up
+	@return:
+		bool - false after the last meeting or a failed request, see MeetingIterator.Err()
+*/
+
+func (iterator *MeetingIterator) Next(ctx context.Context) bool {
+	if iterator.err != nil {
+		return false
+	}
+	for iterator.index+1 >= len(iterator.page.Meetings) {
+		if iterator.started && iterator.page.NextCursor == "" {
+			return false
+		}
+		page, err := iterator.fetch(ctx, iterator.page.NextCursor)
+		if err != nil {
+			iterator.err = err
+			return false
+		}
+		iterator.page, iterator.index, iterator.started = page, -1, true
+	}
+	iterator.index++
+	return true
+}
+
+/*
+	function MeetingIterator.Meeting()
+	@return:
+		Meeting - the current meeting, valid after MeetingIterator.Next() returned true
+*/
+
+func (iterator *MeetingIterator) Meeting() Meeting {
+	return iterator.page.Meetings[iterator.index]
+}
+
+/*
+	function MeetingIterator.Total()
+	@return:
+		int64 - number of matching meetings, known after the first call of MeetingIterator.Next()
+*/
+
+func (iterator *MeetingIterator) Total() int64 {
+	return iterator.page.Total
+}
+
+/*
+	function MeetingIterator.Err()
+	@return:
+		error - nil | error of the failed page request
+*/
+
+func (iterator *MeetingIterator) Err() error {
+	return iterator.err
+}
@@ -0,0 +1,354 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// envPrefix starts the environment variables of the settings, so they do not pick up variables of other programs
+const envPrefix = "MEETINGS_"
+
+/*
+	type Config - settings of the server
+	@attributes:
+		ListenAddr - address of the http server
+		GRPCAddr - address of the gRPC MeetingService, empty for none
+		MongoURI - connection string of the mongoDb deployment, may carry the credentials
+		DBName - name of the database
+		ConnectTimeout - timeout of connecting to mongoDb
+		DBTimeout - timeout of each mongoDb operation
+		ReadHeaderTimeout - time a client has to send the request headers, 0 for none
+		IdleTimeout - time a keep-alive connection waits for the next request, 0 for none
+		PageSize - size of each page when a request has no page_size
+		MaxPageSize - largest page_size a request may ask for
+		PublicHost - host (with the port if it is not the default one) browsers reach the api at, /api/live only accepts
+			websockets opened by pages of it, the Host of the request if empty
+		SMTPAddr, SMTPFrom, SMTPUsername, SMTPPassword - SMTP relay of the mailer, no mails are sent if SMTPAddr is empty
+		EnableGraphQL - serve /graphql
+		EnableDocs - serve /api/openapi.json and /api/docs
+		EnableWebhooks - deliver the events to the webhooks of the store and serve /api/webhooks
+		PrivateWebhooks - accept webhooks of loopback, private and link-local addresses, for development
+		EnableReminders - run the reminder scheduler
+
+	Every setting has a yaml key (the yaml tag), an environment variable (envPrefix and the key in upper case) and a flag
+	(the key with dashes), e.g. page_size, MEETINGS_PAGE_SIZE and -page-size. The durations are written like 10s or 2m.
+	The gRPC listener and the Enable features are off unless they are configured.
+	No timeout is set for writing responses, the streams of /api/meetings/stream and /api/live stay open.
+*/
+
+type Config struct {
+	ListenAddr        string        `yaml:"listen_addr" usage:"address of the http server"`
+	GRPCAddr          string        `yaml:"grpc_addr" usage:"address of the gRPC MeetingService, empty for none"`
+	MongoURI          string        `yaml:"mongo_uri" usage:"connection string of the mongoDb deployment"`
+	DBName            string        `yaml:"db_name" usage:"name of the database"`
+	ConnectTimeout    time.Duration `yaml:"connect_timeout" usage:"timeout of connecting to mongoDb"`
+	DBTimeout         time.Duration `yaml:"db_timeout" usage:"timeout of each mongoDb operation"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" usage:"time a client has to send the request headers, 0 for none"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" usage:"time a keep-alive connection waits for the next request, 0 for none"`
+	PageSize          int           `yaml:"page_size" usage:"size of each page when a request has no page_size"`
+	MaxPageSize       int           `yaml:"max_page_size" usage:"largest page_size a request may ask for"`
+	PublicHost        string        `yaml:"public_host" usage:"host[:port] of the pages allowed to open /api/live, the Host of the request if empty"`
+	SMTPAddr          string        `yaml:"smtp_addr" usage:"host:port of the SMTP relay of the invitations, empty to send none"`
+	SMTPFrom          string        `yaml:"smtp_from" usage:"sender of the invitations"`
+	SMTPUsername      string        `yaml:"smtp_username" usage:"PLAIN authentication username, none if empty"`
+	SMTPPassword      string        `yaml:"smtp_password" usage:"PLAIN authentication password"`
+	EnableGraphQL     bool          `yaml:"enable_graphql" usage:"serve /graphql"`
+	EnableDocs        bool          `yaml:"enable_docs" usage:"serve /api/openapi.json and /api/docs"`
+	EnableWebhooks    bool          `yaml:"enable_webhooks" usage:"deliver events to webhooks and serve /api/webhooks"`
+	PrivateWebhooks   bool          `yaml:"private_webhooks" usage:"accept webhooks of loopback, private and link-local addresses"`
+	EnableReminders   bool          `yaml:"enable_reminders" usage:"run the reminder scheduler"`
+}
+
+/*
+	function DefaultConfig()
+	@return:
+		*Config - settings for a local mongoDb with only the http listener and no optional features,
+			used for everything the file, environment and flags leave out
+*/
+
+func DefaultConfig() *Config {
+	return &Config{
+		ListenAddr:        ":8080",
+		MongoURI:          "mongodb://localhost:27017",
+		DBName:            "appointy-api",
+		ConnectTimeout:    10 * time.Second,
+		DBTimeout:         10 * time.Second,
+		ReadHeaderTimeout: 10 * time.Second,
+		IdleTimeout:       2 * time.Minute,
+		PageSize:          10,
+		MaxPageSize:       100,
+	}
+}
+
+/*
+	type setting - one field of Config
+	@attributes:
+		key - yaml key
+		env - environment variable
+		flag - flag name
+		usage - help of the flag
+		value - the field
+*/
+
+type setting struct {
+	key   string
+	env   string
+	flag  string
+	usage string
+	value reflect.Value
+}
+
+/*
+	function Config.settings()
+	@return:
+		[]setting - the fields of config in declaration order
+*/
+
+func (config *Config) settings() []setting {
+	value := reflect.ValueOf(config).Elem()
+	settings := make([]setting, value.NumField())
+	for i := range settings {
+		field := value.Type().Field(i)
+		key := field.Tag.Get("yaml")
+		settings[i] = setting{
+			key:   key,
+			env:   envPrefix + strings.ToUpper(key),
+			flag:  strings.ReplaceAll(key, "_", "-"),
+			usage: field.Tag.Get("usage"),
+			value: value.Field(i),
+		}
+	}
+	return settings
+}
+
+/*
+	function setting.set()
+	@params:
+		raw - value as written in the environment or on the command line
+	@return:
+		error - nil | error if raw is no value of the field
+*/
+
+func (s setting) set(raw string) error {
+	switch s.value.Interface().(type) {
+	case time.Duration:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("%q is no duration", raw)
+		}
+		s.value.SetInt(int64(d))
+	case int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("%q is no number", raw)
+		}
+		s.value.SetInt(int64(n))
+	case bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("%q is no boolean", raw)
+		}
+		s.value.SetBool(b)
+	default:
+		s.value.SetString(raw)
+	}
+	return nil
+}
+
+/*
+	type flagValue - flag.Value of a setting which keeps the value until the file and environment are read
+	@attributes:
+		setting - the setting of the flag
+		raw - the value on the command line
+		isBool - the flag may be given without a value
+*/
+
+type flagValue struct {
+	setting setting
+	raw     *string
+	isBool  bool
+}
+
+func (value flagValue) String() string {
+	if value.raw == nil {
+		return ""
+	}
+	return *value.raw
+}
+
+func (value flagValue) Set(raw string) error {
+	// check the value now so that the flag package reports it with the usage
+	check := setting{value: reflect.New(value.setting.value.Type()).Elem()}
+	if err := check.set(raw); err != nil {
+		return err
+	}
+	*value.raw = raw
+	return nil
+}
+
+func (value flagValue) IsBoolFlag() bool { return value.isBool }
+
+/*
+	function LoadConfig()
+	@params:
+		args - command line arguments without the program name
+		lookupEnv - looks up an environment variable, os.LookupEnv
+		output - writer of the flag usage and errors
+	@description:
+		Start from DefaultConfig(), then apply the yaml file of -config or MEETINGS_CONFIG_FILE, then the environment variables,
+		then the flags, so each source overrides the ones before. Unknown keys in the file are errors.
+		The result is validated with Config.Validate()
+	@return:
+		*Config - the settings
+		error - nil | flag.ErrHelp for -h | error naming the invalid source and setting
+*/
+
+func LoadConfig(args []string, lookupEnv func(string) (string, bool), output io.Writer) (*Config, error) {
+	config := DefaultConfig()
+	settings := config.settings()
+
+	flags := flag.NewFlagSet("api", flag.ContinueOnError)
+	flags.SetOutput(output)
+	file, _ := lookupEnv(envPrefix + "CONFIG_FILE")
+	flags.StringVar(&file, "config", file, "yaml file with the settings ("+envPrefix+"CONFIG_FILE)")
+	raw := make([]*string, len(settings))
+	for i, s := range settings {
+		raw[i] = new(string)
+		_, isBool := s.value.Interface().(bool)
+		flags.Var(flagValue{setting: s, raw: raw[i], isBool: isBool}, s.flag, fmt.Sprintf("%s (%s, default %v)", s.usage, s.env, s.value.Interface()))
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if flags.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected argument %q", flags.Arg(0))
+	}
+
+	if file != "" {
+		if err := config.readFile(file); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, s := range settings {
+		if value, ok := lookupEnv(s.env); ok {
+			if err := s.set(value); err != nil {
+				return nil, fmt.Errorf("environment variable %s: %v", s.env, err)
+			}
+		}
+	}
+
+	given := map[string]bool{}
+	flags.Visit(func(f *flag.Flag) { given[f.Name] = true })
+	for i, s := range settings {
+		if given[s.flag] {
+			s.set(*raw[i])
+		}
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+/*
+	function Config.readFile()
+	@params:
+		path - yaml file, its keys are the yaml tags of Config
+	@return:
+		error - nil | error if the file can not be read, is no yaml or has unknown keys
+*/
+
+func (config *Config) readFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("config file: %v", err)
+	}
+	defer file.Close()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(config); err != nil && err != io.EOF {
+		return fmt.Errorf("config file %s: %v", path, err)
+	}
+	return nil
+}
+
+/*
+	function Config.Validate()
+	@description:
+		check the addresses, the public host, the mongoDb settings, the timeouts, the page sizes and that the SMTP settings are complete
+	@return:
+		error - nil | error listing every invalid setting by its yaml key
+*/
+
+func (config *Config) Validate() error {
+	var problems []string
+	invalid := func(key, format string, args ...interface{}) {
+		problems = append(problems, key+": "+fmt.Sprintf(format, args...))
+	}
+
+	if _, _, err := net.SplitHostPort(config.ListenAddr); err != nil {
+		invalid("listen_addr", "%q is no host:port", config.ListenAddr)
+	}
+	if config.GRPCAddr != "" {
+		if _, _, err := net.SplitHostPort(config.GRPCAddr); err != nil {
+			invalid("grpc_addr", "%q is no host:port", config.GRPCAddr)
+		} else if config.GRPCAddr == config.ListenAddr {
+			invalid("grpc_addr", "same address as listen_addr")
+		}
+	}
+	if !strings.HasPrefix(config.MongoURI, "mongodb://") && !strings.HasPrefix(config.MongoURI, "mongodb+srv://") {
+		invalid("mongo_uri", "must start with mongodb:// or mongodb+srv://")
+	}
+	if config.DBName == "" || strings.ContainsAny(config.DBName, "/\\. \"$*<>:|?") {
+		invalid("db_name", "%q is no mongoDb database name", config.DBName)
+	}
+	for _, s := range config.settings() {
+		if d, ok := s.value.Interface().(time.Duration); ok && d < 0 {
+			invalid(s.key, "must not be negative")
+		}
+	}
+	if config.ConnectTimeout == 0 {
+		invalid("connect_timeout", "must be positive")
+	}
+	if config.DBTimeout == 0 {
+		invalid("db_timeout", "must be positive")
+	}
+	if config.PageSize < 1 {
+		invalid("page_size", "must be at least 1")
+	}
+	if config.MaxPageSize < config.PageSize {
+		invalid("max_page_size", "must be at least page_size")
+	}
+	if strings.ContainsAny(config.PublicHost, "/ ") {
+		invalid("public_host", "%q is no host[:port]", config.PublicHost)
+	}
+	if config.SMTPAddr != "" {
+		if _, _, err := net.SplitHostPort(config.SMTPAddr); err != nil {
+			invalid("smtp_addr", "%q is no host:port", config.SMTPAddr)
+		}
+		if config.SMTPFrom == "" {
+			invalid("smtp_from", "required with smtp_addr")
+		}
+	}
+	if config.SMTPPassword != "" && config.SMTPUsername == "" {
+		invalid("smtp_username", "required with smtp_password")
+	}
+
+	if len(problems) > 0 {
+		return errors.New("invalid config: " + strings.Join(problems, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,310 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dxmxnlord/golang-api/src/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// csvColumns are the columns of a meetings csv, a meeting takes one row per participant
+var csvColumns = []string{"id", "title", "start_time", "end_time", "status", "recurrence", "exceptions", "overrides",
+	"reminders", "participant_name", "participant_email", "participant_rsvp"}
+
+// csvExportPageSize is the number of meetings fetched from the store per page while streaming an export
+const csvExportPageSize = 500
+
+/*
+	type CSVImportResult - outcome of importing the rows of one meeting
+	@attributes:
+		Rows - line numbers of the rows, the header is line 1
+		InsertedID - id of the created meeting, missing if the import failed
+		Error - reason the import failed, empty on success
+*/
+
+type CSVImportResult struct {
+	Rows       []int               `json:"rows"`
+	InsertedID *primitive.ObjectID `json:"InsertedID,omitempty"`
+	Error      string              `json:"error,omitempty"`
+}
+
+/*
+	function writeCSV()
+	@params:
+		w - writer for the csv
+		fetch - returns the meetings of a keyset page, like getMeetings() or getMeetingsParticipant()
+		flush - called after each page so that the response is streamed, may be nil
+	@description:
+		write the header, then walk the pages of fetch by cursor and write one row per participant of every meeting,
+		or a single row without participant for a meeting without participants
+		overrides are written as a json array and reminders as minutes separated by spaces, so that a series round-trips
+		titles, names and emails that a spreadsheet would run as a formula are escaped with csvCell()
+		only one page is held in memory at a time
+	@return:
+		error - nil | error
+*/
+
+func writeCSV(w io.Writer, fetch func(Paging) (MeetingPage, error), flush func()) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvColumns); err != nil {
+		return err
+	}
+
+	paging := Paging{Keyset: true, Size: csvExportPageSize}
+	for {
+		page, err := fetch(paging)
+		if err != nil {
+			return err
+		}
+		for _, meeting := range page.Meetings {
+			var exceptions, reminders []string
+			for _, exception := range meeting.Exceptions {
+				exceptions = append(exceptions, exception.UTC().Format(time.RFC3339))
+			}
+			for _, minutes := range meeting.Reminders {
+				reminders = append(reminders, strconv.Itoa(minutes))
+			}
+			overrides := ""
+			if len(meeting.Overrides) > 0 {
+				encoded, err := json.Marshal(meeting.Overrides)
+				if err != nil {
+					return err
+				}
+				overrides = string(encoded)
+			}
+			row := []string{meeting.ID.Hex(), csvCell(meeting.Title), meeting.StartTime.UTC().Format(time.RFC3339),
+				meeting.EndTime.UTC().Format(time.RFC3339), meeting.Status, meeting.Recurrence, strings.Join(exceptions, " "),
+				overrides, strings.Join(reminders, " ")}
+
+			participants := meeting.Participants
+			if len(participants) == 0 {
+				participants = []Participant{{}}
+			}
+			for _, participant := range participants {
+				if err := writer.Write(append(row, csvCell(participant.Name), csvCell(participant.Email), participant.RSVP)); err != nil {
+					return err
+				}
+			}
+		}
+
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return err
+		}
+		if flush != nil {
+			flush()
+		}
+		if page.Next == nil {
+			return nil
+		}
+		paging.After = page.Next
+	}
+}
+
+/*
+	function importCSV()
+	@purpose:
+		create meetings from an uploaded csv
+	@params:
+		store - MeetingStore instance
+		r - reader of the csv
+	@description:
+		the header names the columns in any order, title, start_time and end_time are required
+		consecutive rows with the same id and start_time are the participants of one meeting, rows without id are single meetings,
+		so an export can be imported again. The id itself is not kept, meetings get new ids
+		times are RFC 3339, exceptions and reminders are separated by spaces and overrides are a json array
+		the ' that csvCell() put in front of a cell is removed again
+		every meeting is created with Meeting.createMeeting(), one after the other,
+		so imported rows run through the same validation and overlap checks as the Api
+	@return:
+		[]CSVImportResult - one result per meeting in file order
+		error - nil | error if the header is invalid
+*/
+
+func importCSV(store MeetingStore, r io.Reader) ([]CSVImportResult, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.New("Invalid csv header")
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"title", "start_time", "end_time"} {
+		if _, ok := columns[name]; !ok {
+			return nil, errors.New("Missing csv column " + name)
+		}
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return csvValue(strings.TrimSpace(record[i]))
+		}
+		return ""
+	}
+
+	var results []CSVImportResult
+	var meeting Meeting
+	var group CSVImportResult
+	var key string
+	var groupErr error
+	finish := func() {
+		if len(group.Rows) == 0 {
+			return
+		}
+		if groupErr == nil {
+			var id primitive.ObjectID
+			if id, groupErr = meeting.createMeeting(store); groupErr == nil {
+				group.InsertedID = &id
+			}
+		}
+		if groupErr != nil {
+			group.Error = groupErr.Error()
+		}
+		results = append(results, group)
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			parseErr, ok := err.(*csv.ParseError)
+			if !ok {
+				return nil, err
+			}
+			finish()
+			results = append(results, CSVImportResult{Rows: []int{parseErr.StartLine}, Error: err.Error()})
+			meeting, group, key, groupErr = Meeting{}, CSVImportResult{}, "", nil
+			continue
+		}
+
+		rowKey := ""
+		if id := field(record, "id"); id != "" {
+			rowKey = id + " " + field(record, "start_time")
+		}
+		if rowKey == "" || rowKey != key {
+			finish()
+			group, key = CSVImportResult{}, rowKey
+			meeting, groupErr = meetingFromRecord(func(name string) string { return field(record, name) })
+		}
+		line, _ := reader.FieldPos(0)
+		group.Rows = append(group.Rows, line)
+
+		if email := field(record, "participant_email"); email != "" && groupErr == nil {
+			participant := Participant{Name: field(record, "participant_name"), Email: email, RSVP: field(record, "participant_rsvp")}
+			if participant.RSVP == "" {
+				participant.RSVP = "Not Answered"
+			}
+			meeting.Participants = append(meeting.Participants, participant)
+		}
+	}
+	finish()
+	return results, nil
+}
+
+/*
+	function meetingFromRecord()
+	@params:
+		field - returns the value of a column of the first row of the meeting
+	@return:
+		Meeting - meeting without participants
+		error - nil | error if a time, an override or a reminder does not parse
+*/
+
+func meetingFromRecord(field func(string) string) (Meeting, error) {
+	meeting := Meeting{Meeting: model.Meeting{Title: field("title"), Status: field("status"), Recurrence: field("recurrence")}}
+
+	var err error
+	if meeting.StartTime, err = time.Parse(time.RFC3339, field("start_time")); err != nil {
+		return meeting, errors.New("Invalid start_time " + field("start_time"))
+	}
+	if meeting.EndTime, err = time.Parse(time.RFC3339, field("end_time")); err != nil {
+		return meeting, errors.New("Invalid end_time " + field("end_time"))
+	}
+	meeting.StartTime, meeting.EndTime = meeting.StartTime.UTC(), meeting.EndTime.UTC()
+
+	for _, value := range strings.Fields(field("exceptions")) {
+		exception, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return meeting, errors.New("Invalid exception " + value)
+		}
+		meeting.Exceptions = append(meeting.Exceptions, exception.UTC())
+	}
+
+	if overrides := field("overrides"); overrides != "" {
+		if err := json.Unmarshal([]byte(overrides), &meeting.Overrides); err != nil {
+			return meeting, errors.New("Invalid overrides " + overrides)
+		}
+	}
+
+	for _, value := range strings.Fields(field("reminders")) {
+		minutes, err := strconv.Atoi(value)
+		if err != nil {
+			return meeting, errors.New("Invalid reminder " + value)
+		}
+		meeting.Reminders = append(meeting.Reminders, minutes)
+	}
+	return meeting, nil
+}
+
+/*
+	function csvFormula()
+	@params:
+		value - value of a cell
+	@return:
+		bool - true if a spreadsheet would run the cell as a formula, or if it starts with ' followed by such a value,
+			so that csvValue() can tell an escaped cell from one that starts with ' itself
+*/
+
+func csvFormula(value string) bool {
+	if value == "" {
+		return false
+	}
+	switch value[0] {
+	case '=', '+', '-', '@', '\t', '\r':
+		return true
+	case '\'':
+		return csvFormula(value[1:])
+	}
+	return false
+}
+
+/*
+	function csvCell()
+	@params:
+		value - text of a cell written by an export
+	@return:
+		string - value with a leading ' if csvFormula() holds, so that spreadsheets show it as text
+*/
+
+func csvCell(value string) string {
+	if csvFormula(value) {
+		return "'" + value
+	}
+	return value
+}
+
+/*
+	function csvValue()
+	@params:
+		value - cell of an imported csv
+	@return:
+		string - value without the ' that csvCell() added
+*/
+
+func csvValue(value string) string {
+	if strings.HasPrefix(value, "'") && csvFormula(value[1:]) {
+		return value[1:]
+	}
+	return value
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+/*
+	type MeetingCursor - position in a listing ordered by start time and id
+	@attributes:
+		StartTime - start time of the last meeting already returned
+		ID - id of the last meeting already returned
+	@description:
+		Keyset pagination continues after this position instead of skipping a number of meetings,
+		so meetings inserted while a client pages through a listing do not shift the following pages
+*/
+
+type MeetingCursor struct {
+	StartTime time.Time
+	ID        primitive.ObjectID
+}
+
+/*
+	function cursorAfter()
+	@params:
+		meeting - last meeting of a page
+	@return:
+		*MeetingCursor - cursor continuing after meeting
+*/
+
+func cursorAfter(meeting Meeting) *MeetingCursor {
+	return &MeetingCursor{StartTime: meeting.StartTime, ID: meeting.ID}
+}
+
+/*
+	function MeetingCursor.isBefore()
+	@params:
+		meeting - meeting to compare
+	@return:
+		bool - true if meeting comes after the cursor position
+*/
+
+func (cursor *MeetingCursor) isBefore(meeting Meeting) bool {
+	if !meeting.StartTime.Equal(cursor.StartTime) {
+		return meeting.StartTime.After(cursor.StartTime)
+	}
+	return meeting.ID.Hex() > cursor.ID.Hex()
+}
+
+/*
+	function MeetingCursor.String()
+	@return:
+		string - opaque url safe token of the cursor
+*/
+
+func (cursor *MeetingCursor) String() string {
+	raw := strconv.FormatInt(cursor.StartTime.UnixNano(), 10) + ":" + cursor.ID.Hex()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+/*
+	function parseCursor()
+	@params:
+		token - token from MeetingCursor.String()
+	@return:
+		*MeetingCursor - decoded cursor
+		error - nil | error
+*/
+
+func parseCursor(token string) (*MeetingCursor, error) {
+	invalid := errors.New("Invalid cursor value")
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, invalid
+	}
+	parts := strings.SplitN(string(raw), ":", 2)
+	if len(parts) != 2 {
+		return nil, invalid
+	}
+	nanos, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return nil, invalid
+	}
+	id, err := primitive.ObjectIDFromHex(parts[1])
+	if err != nil {
+		return nil, invalid
+	}
+	return &MeetingCursor{StartTime: time.Unix(0, nanos).UTC(), ID: id}, nil
+}
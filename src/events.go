@@ -0,0 +1,103 @@
+package main
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// meeting lifecycle event types
+const (
+	EventMeetingCreated   = "meeting.created"
+	EventMeetingUpdated   = "meeting.updated"
+	EventMeetingCancelled = "meeting.cancelled"
+	EventMeetingDeleted   = "meeting.deleted"
+	EventRSVPChanged      = "rsvp.changed"
+	EventMeetingReminder  = "meeting.reminder"
+)
+
+// eventTypes are the event types clients can subscribe to
+var eventTypes = []string{EventMeetingCreated, EventMeetingUpdated, EventMeetingCancelled, EventMeetingDeleted, EventRSVPChanged, EventMeetingReminder}
+
+/*
+	type Event - something that happened to a meeting
+	@attributes:
+		ID - unique id of the event, receivers use it to detect repeated deliveries
+		Type - one of eventTypes
+		CreatedAt - time of the write
+		Meeting - the meeting as written, the removed meeting for meeting.deleted, the occurrence for meeting.reminder
+		Previous - the meeting before the write, for writes replacing a meeting only
+		Email - participant whose rsvp changed, for rsvp.changed only
+		Minutes - minutes between the reminder and the start, for meeting.reminder only, left out for reminders at the start
+		Recipients - emails of the participants to remind, for meeting.reminder only
+*/
+
+type Event struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id"`
+	Type       string             `json:"type" bson:"type"`
+	CreatedAt  time.Time          `json:"created_at" bson:"created_at"`
+	Meeting    Meeting            `json:"meeting" bson:"meeting"`
+	Previous   *Meeting           `json:"previous,omitempty" bson:"previous,omitempty"`
+	Email      string             `json:"email,omitempty" bson:"email,omitempty"`
+	Minutes    int                `json:"minutes,omitempty" bson:"minutes,omitempty"`
+	Recipients []string           `json:"recipients,omitempty" bson:"recipients,omitempty"`
+}
+
+/*
+	interface Publisher - a channel the events of the outbox are delivered through
+	@methods:
+		Publish - deliver the event, return once it is delivered or given up. An error hands the event over again later,
+			so the channel has to cope with repeated events. Event types the channel does not handle are ignored
+	@description:
+		WebhookDispatcher and Mailer are the built-in channels, others are added to Api.Channels
+*/
+
+type Publisher interface {
+	Publish(event Event) error
+}
+
+/*
+	function Event.of()
+	@params:
+		meeting - meeting as written by the store
+	@return:
+		Event - the event with a new ID, the current time and a copy of meeting
+*/
+
+func (event Event) of(meeting *Meeting) Event {
+	event.ID = primitive.NewObjectID()
+	event.CreatedAt = time.Now()
+	event.Meeting = copyMeeting(*meeting)
+	return event
+}
+
+/*
+	function Event.replacing()
+	@params:
+		previous - meeting as stored before the write
+		meeting - meeting as written by the store
+	@return:
+		Event - Event.of() meeting with a copy of previous
+*/
+
+func (event Event) replacing(previous Meeting, meeting *Meeting) Event {
+	event = event.of(meeting)
+	previous = copyMeeting(previous)
+	event.Previous = &previous
+	return event
+}
+
+/*
+	function knownEventType()
+	@return:
+		bool - true if name is one of eventTypes
+*/
+
+func knownEventType(name string) bool {
+	for _, eventType := range eventTypes {
+		if eventType == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"sort"
+	"time"
+)
+
+/*
+	type Interval - a busy time range
+	@attributes:
+		Start, End - times of the range
+*/
+
+type Interval struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+/*
+	function getBusy()
+	@purpose:
+		get the busy times of a participant
+	@params:
+		store - MeetingStore instance
+		email - participant email
+		st_time, en_time - time range
+		includeMaybe - also count meetings with rsvp Maybe as busy
+	@description:
+		query the store for the active meetings the participant is going for with an occurrence in the range,
+		so only the meetings of the range are loaded, and expand their occurrences between starttime and endtime
+		clip the occurrences to the range and merge them into busy intervals, which carry no meeting details
+	@return:
+		[]Interval - sorted non overlapping busy intervals
+		error - nil | error
+*/
+
+func getBusy(store MeetingStore, email string, st_time, en_time time.Time, includeMaybe bool) ([]Interval, error) {
+	meetings, err := store.FindOverlapping(email, st_time, en_time, includeMaybe)
+	if err != nil {
+		return nil, err
+	}
+
+	var busy []Interval
+	for _, meeting := range meetings {
+		for _, occurrence := range meeting.occurrences(st_time, en_time) {
+			interval := Interval{Start: occurrence.StartTime, End: occurrence.EndTime}
+			if interval.Start.Before(st_time) {
+				interval.Start = st_time
+			}
+			if interval.End.After(en_time) {
+				interval.End = en_time
+			}
+			busy = append(busy, interval)
+		}
+	}
+	return mergeIntervals(busy), nil
+}
+
+/*
+	function mergeIntervals()
+	@params:
+		intervals - intervals in any order
+	@return:
+		[]Interval - sorted intervals where overlapping and touching intervals are joined
+*/
+
+func mergeIntervals(intervals []Interval) []Interval {
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i].Start.Before(intervals[j].Start) })
+
+	merged := []Interval{}
+	for _, interval := range intervals {
+		last := len(merged) - 1
+		if last >= 0 && !interval.Start.After(merged[last].End) {
+			if interval.End.After(merged[last].End) {
+				merged[last].End = interval.End
+			}
+			continue
+		}
+		merged = append(merged, interval)
+	}
+	return merged
+}
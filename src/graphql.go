@@ -0,0 +1,613 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/dxmxnlord/golang-api/src/model"
+	graphql "github.com/graph-gophers/graphql-go"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxGraphQLBytes limits the body of one graphql request
+const maxGraphQLBytes = 1 << 20
+
+// maxGraphQLDepth limits the nesting of the selections of one graphql query
+const maxGraphQLDepth = 10
+
+// graphqlSchema is served on /graphql, the resolvers are below
+const graphqlSchema = `
+schema {
+	query: Query
+	mutation: Mutation
+}
+
+"RFC 3339 time"
+scalar Time
+
+type Query {
+	"the meeting with id, null if there is none"
+	meeting(id: ID!): Meeting
+	"meetings matching all given filters, start and end go together, occurrences of series are expanded within them"
+	meetings(email: String, start: Time, end: Time, includeCancelled: Boolean = false, first: Int, after: String): MeetingConnection!
+}
+
+type Mutation {
+	createMeeting(input: MeetingInput!): Meeting!
+	"changes only the given fields, like PATCH /api/meetings/{id}"
+	updateMeeting(id: ID!, input: MeetingInput!): Meeting!
+	setRSVP(id: ID!, email: String!, rsvp: String!): Meeting!
+}
+
+type Meeting {
+	id: ID!
+	title: String!
+	startTime: Time!
+	endTime: Time!
+	createdAt: Time
+	participants: [Participant!]!
+	"cancelled, null for active meetings"
+	status: String
+	recurrence: String
+	exceptions: [Time!]!
+	overrides: [Override!]!
+	seriesEnd: Time
+	"start of the occurrence as generated by the rule, for expanded occurrences only"
+	recurrenceId: Time
+	reminders: [Int!]!
+}
+
+type Participant {
+	email: String!
+	name: String!
+	rsvp: String!
+}
+
+type Override {
+	recurrenceId: Time!
+	title: String
+	startTime: Time!
+	endTime: Time!
+}
+
+type MeetingConnection {
+	nodes: [Meeting!]!
+	totalCount: Int!
+	"pass as after for the next page, null on the last page"
+	endCursor: String
+}
+
+input MeetingInput {
+	title: String
+	startTime: Time
+	endTime: Time
+	participants: [ParticipantInput!]
+	status: String
+	recurrence: String
+	exceptions: [Time!]
+	overrides: [OverrideInput!]
+	reminders: [Int!]
+}
+
+input ParticipantInput {
+	email: String!
+	name: String!
+	rsvp: String!
+}
+
+input OverrideInput {
+	recurrenceId: Time!
+	title: String
+	startTime: Time!
+	endTime: Time!
+}
+`
+
+/*
+	type graphqlHandler - serves graphql requests on the schema
+	@attributes:
+		schema - graphqlSchema with the resolvers of an Api
+*/
+
+type graphqlHandler struct {
+	schema *graphql.Schema
+}
+
+/*
+	function newGraphQLHandler()
+	@params:
+		api - Api whose store the resolvers use
+	@return:
+		*graphqlHandler - handler of the /graphql route
+*/
+
+func newGraphQLHandler(api *Api) *graphqlHandler {
+	schema := graphql.MustParseSchema(graphqlSchema, &graphqlResolver{api: api},
+		graphql.UseFieldResolvers(), graphql.MaxDepth(maxGraphQLDepth))
+	return &graphqlHandler{schema: schema}
+}
+
+/*
+	function graphqlHandler.ServeHTTP()
+	@params:
+		http.ResponseWriter - for giving back a response
+		http.Request - the original http request
+	@description:
+		Check if the method is POST, else return error response
+		Decode the body {"query", "operationName", "variables"}, else return error response
+		Execute the query and respond with its {"data", "errors"} with 200 as graphql clients expect,
+		errors of the meeting functions carry the status the REST routes would answer with in their extensions
+*/
+
+func (handler *graphqlHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		errorResponse(w, http.StatusMethodNotAllowed, "Invalid Request Method for this endpoint")
+		return
+	}
+
+	var params struct {
+		Query         string                 `json:"query"`
+		OperationName string                 `json:"operationName"`
+		Variables     map[string]interface{} `json:"variables"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxGraphQLBytes)).Decode(&params); err != nil || params.Query == "" {
+		errorResponse(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	response := handler.schema.Exec(r.Context(), params.Query, params.OperationName, params.Variables)
+	jsonResponse(w, http.StatusOK, response)
+}
+
+/*
+	type graphqlError - error of a resolver
+	@attributes:
+		message - message of the error
+		status - http status the REST routes answer the error with
+*/
+
+type graphqlError struct {
+	message string
+	status  int
+}
+
+func (err *graphqlError) Error() string {
+	return err.message
+}
+
+/*
+	function graphqlError.Extensions()
+	@return:
+		map[string]interface{} - extensions of the error in the response, {"status": status}
+*/
+
+func (err *graphqlError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"status": err.status}
+}
+
+/*
+	function meetingGraphQLError()
+	@params:
+		err - error from a meeting function
+	@return:
+		error - *graphqlError with the status of meetingErrorResponse(), for mutations of an existing meeting
+*/
+
+func meetingGraphQLError(err error) error {
+	if _, ok := err.(*OverlapError); ok || err == ErrMeetingChanged {
+		return &graphqlError{err.Error(), http.StatusConflict}
+	} else if err == ErrMeetingNotFound || err == ErrParticipantNotFound {
+		return &graphqlError{err.Error(), http.StatusNotFound}
+	}
+	return &graphqlError{err.Error(), http.StatusBadRequest}
+}
+
+/*
+	type graphqlResolver - root resolver of the queries and mutations
+	@attributes:
+		api - Api with the store and the page sizes
+*/
+
+type graphqlResolver struct {
+	api *Api
+}
+
+/*
+	function graphqlResolver.Meeting()
+	@params:
+		args - id of the meeting
+	@return:
+		*meetingResolver - the meeting, nil if there is none
+		error - nil | error of the store
+*/
+
+func (resolver *graphqlResolver) Meeting(args struct{ ID graphql.ID }) (*meetingResolver, error) {
+	id, err := primitive.ObjectIDFromHex(string(args.ID))
+	if err != nil {
+		return nil, nil
+	}
+	meeting := Meeting{Meeting: model.Meeting{ID: id}}
+	if err := meeting.getMeeting(resolver.api.Store); err == ErrMeetingNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, &graphqlError{err.Error(), http.StatusInternalServerError}
+	}
+	return &meetingResolver{meeting}, nil
+}
+
+/*
+	function graphqlResolver.Meetings()
+	@params:
+		args - filters and page of the query
+	@description:
+		Unlike `Api.getMeetingsHandler()` the email and the time range can be combined, both are passed to searchMeetings()
+		The listing is keyset paged: first defaults to Api.PageSize and is capped at Api.MaxPageSize, after is the
+		endCursor of the previous page
+	@return:
+		*meetingConnectionResolver - page of meetings
+		error - nil | *graphqlError
+*/
+
+func (resolver *graphqlResolver) Meetings(args struct {
+	Email            *string
+	Start            *graphql.Time
+	End              *graphql.Time
+	IncludeCancelled bool
+	First            *int32
+	After            *string
+}) (*meetingConnectionResolver, error) {
+	query := MeetingQuery{IncludeCancelled: args.IncludeCancelled}
+	if args.Email != nil {
+		query.Email = *args.Email
+	}
+	if (args.Start == nil) != (args.End == nil) {
+		return nil, &graphqlError{"Missing starting or Ending time", http.StatusBadRequest}
+	}
+	if args.Start != nil {
+		query.Start, query.End = args.Start.UTC(), args.End.UTC()
+	}
+
+	paging := Paging{Size: resolver.api.PageSize, Keyset: true}
+	if args.First != nil {
+		if *args.First < 1 {
+			return nil, &graphqlError{"Invalid first value", http.StatusBadRequest}
+		}
+		paging.Size = int(*args.First)
+	}
+	if paging.Size > resolver.api.MaxPageSize {
+		paging.Size = resolver.api.MaxPageSize
+	}
+	if args.After != nil && *args.After != "" {
+		after, err := parseCursor(*args.After)
+		if err != nil {
+			return nil, &graphqlError{err.Error(), http.StatusBadRequest}
+		}
+		paging.After = after
+	}
+
+	page, err := searchMeetings(resolver.api.Store, query, paging)
+	if err != nil {
+		return nil, &graphqlError{err.Error(), http.StatusInternalServerError}
+	}
+	return &meetingConnectionResolver{page}, nil
+}
+
+/*
+	type meetingInput - fields of a meeting in a mutation, nil for fields not given
+*/
+
+type meetingInput struct {
+	Title        *string
+	StartTime    *graphql.Time
+	EndTime      *graphql.Time
+	Participants *[]Participant
+	Status       *string
+	Recurrence   *string
+	Exceptions   *[]graphql.Time
+	Overrides    *[]struct {
+		RecurrenceID graphql.Time
+		Title        *string
+		StartTime    graphql.Time
+		EndTime      graphql.Time
+	}
+	Reminders *[]int32
+}
+
+/*
+	function meetingInput.apply()
+	@params:
+		meeting - meeting to change
+	@description:
+		set the given fields on meeting, like a json body is decoded on top of it
+*/
+
+func (input *meetingInput) apply(meeting *Meeting) {
+	if input.Title != nil {
+		meeting.Title = *input.Title
+	}
+	if input.StartTime != nil {
+		meeting.StartTime = input.StartTime.UTC()
+	}
+	if input.EndTime != nil {
+		meeting.EndTime = input.EndTime.UTC()
+	}
+	if input.Participants != nil {
+		meeting.Participants = *input.Participants
+	}
+	if input.Status != nil {
+		meeting.Status = *input.Status
+	}
+	if input.Recurrence != nil {
+		meeting.Recurrence = *input.Recurrence
+	}
+	if input.Exceptions != nil {
+		meeting.Exceptions = []time.Time{}
+		for _, exception := range *input.Exceptions {
+			meeting.Exceptions = append(meeting.Exceptions, exception.UTC())
+		}
+	}
+	if input.Overrides != nil {
+		meeting.Overrides = []Override{}
+		for _, override := range *input.Overrides {
+			title := ""
+			if override.Title != nil {
+				title = *override.Title
+			}
+			meeting.Overrides = append(meeting.Overrides, Override{
+				RecurrenceID: override.RecurrenceID.UTC(),
+				Title:        title,
+				StartTime:    override.StartTime.UTC(),
+				EndTime:      override.EndTime.UTC(),
+			})
+		}
+	}
+	if input.Reminders != nil {
+		meeting.Reminders = []int{}
+		for _, reminder := range *input.Reminders {
+			meeting.Reminders = append(meeting.Reminders, int(reminder))
+		}
+	}
+}
+
+/*
+	function graphqlResolver.CreateMeeting()
+	@params:
+		args - fields of the new meeting
+	@description:
+		create the meeting with Meeting.createMeeting() like POST /api/meetings
+	@return:
+		*meetingResolver - the created meeting
+		error - nil | *graphqlError
+*/
+
+func (resolver *graphqlResolver) CreateMeeting(args struct{ Input meetingInput }) (*meetingResolver, error) {
+	var meeting Meeting
+	args.Input.apply(&meeting)
+
+	id, err := meeting.createMeeting(resolver.api.Store)
+	if err != nil {
+		// like POST /api/meetings, an overlap of a new meeting is a 400
+		return nil, &graphqlError{err.Error(), http.StatusBadRequest}
+	}
+	meeting.ID = id
+	return &meetingResolver{meeting}, nil
+}
+
+/*
+	function graphqlResolver.UpdateMeeting()
+	@params:
+		args - id of the meeting and the fields to change
+	@description:
+		load the meeting, set the given fields and write it with Meeting.updateMeeting() like PATCH /api/meetings/{id}
+	@return:
+		*meetingResolver - the updated meeting
+		error - nil | *graphqlError
+*/
+
+func (resolver *graphqlResolver) UpdateMeeting(args struct {
+	ID    graphql.ID
+	Input meetingInput
+}) (*meetingResolver, error) {
+	id, _ := primitive.ObjectIDFromHex(string(args.ID))
+	meeting := Meeting{Meeting: model.Meeting{ID: id}}
+	if err := meeting.getMeeting(resolver.api.Store); err != nil {
+		return nil, meetingGraphQLError(err)
+	}
+
+	stored := copyMeeting(meeting)
+	args.Input.apply(&meeting)
+	if err := meeting.updateMeeting(resolver.api.Store, stored); err != nil {
+		return nil, meetingGraphQLError(err)
+	}
+	return &meetingResolver{meeting}, nil
+}
+
+/*
+	function graphqlResolver.SetRSVP()
+	@params:
+		args - id of the meeting, email of the participant and the new rsvp
+	@description:
+		change the rsvp with Meeting.setRSVP() like PUT /api/meetings/{id}/rsvp
+	@return:
+		*meetingResolver - the updated meeting
+		error - nil | *graphqlError
+*/
+
+func (resolver *graphqlResolver) SetRSVP(args struct {
+	ID    graphql.ID
+	Email string
+	RSVP  string
+}) (*meetingResolver, error) {
+	id, _ := primitive.ObjectIDFromHex(string(args.ID))
+	meeting := Meeting{Meeting: model.Meeting{ID: id}}
+	if err := meeting.setRSVP(resolver.api.Store, args.Email, args.RSVP); err != nil {
+		return nil, meetingGraphQLError(err)
+	}
+	return &meetingResolver{meeting}, nil
+}
+
+/*
+	type meetingConnectionResolver - resolves a MeetingConnection
+	@attributes:
+		page - the page of meetings
+*/
+
+type meetingConnectionResolver struct {
+	page MeetingPage
+}
+
+func (resolver *meetingConnectionResolver) Nodes() []*meetingResolver {
+	nodes := []*meetingResolver{}
+	for _, meeting := range resolver.page.Meetings {
+		nodes = append(nodes, &meetingResolver{meeting})
+	}
+	return nodes
+}
+
+func (resolver *meetingConnectionResolver) TotalCount() int32 {
+	return int32(resolver.page.Total)
+}
+
+func (resolver *meetingConnectionResolver) EndCursor() *string {
+	if resolver.page.Next == nil {
+		return nil
+	}
+	cursor := resolver.page.Next.String()
+	return &cursor
+}
+
+/*
+	type meetingResolver - resolves a Meeting, participants are resolved from their fields
+	@attributes:
+		meeting - the meeting
+*/
+
+type meetingResolver struct {
+	meeting Meeting
+}
+
+func (resolver *meetingResolver) ID() graphql.ID {
+	return graphql.ID(resolver.meeting.ID.Hex())
+}
+
+func (resolver *meetingResolver) Title() string {
+	return resolver.meeting.Title
+}
+
+func (resolver *meetingResolver) StartTime() graphql.Time {
+	return graphql.Time{Time: resolver.meeting.StartTime}
+}
+
+func (resolver *meetingResolver) EndTime() graphql.Time {
+	return graphql.Time{Time: resolver.meeting.EndTime}
+}
+
+func (resolver *meetingResolver) CreatedAt() *graphql.Time {
+	return optionalTime(resolver.meeting.CreatedAt)
+}
+
+func (resolver *meetingResolver) Participants() []Participant {
+	if resolver.meeting.Participants == nil {
+		return []Participant{}
+	}
+	return resolver.meeting.Participants
+}
+
+func (resolver *meetingResolver) Status() *string {
+	return optionalString(resolver.meeting.Status)
+}
+
+func (resolver *meetingResolver) Recurrence() *string {
+	return optionalString(resolver.meeting.Recurrence)
+}
+
+func (resolver *meetingResolver) Exceptions() []graphql.Time {
+	exceptions := []graphql.Time{}
+	for _, exception := range resolver.meeting.Exceptions {
+		exceptions = append(exceptions, graphql.Time{Time: exception})
+	}
+	return exceptions
+}
+
+func (resolver *meetingResolver) Overrides() []*overrideResolver {
+	overrides := []*overrideResolver{}
+	for _, override := range resolver.meeting.Overrides {
+		overrides = append(overrides, &overrideResolver{override})
+	}
+	return overrides
+}
+
+func (resolver *meetingResolver) SeriesEnd() *graphql.Time {
+	if resolver.meeting.SeriesEnd == nil {
+		return nil
+	}
+	return optionalTime(*resolver.meeting.SeriesEnd)
+}
+
+func (resolver *meetingResolver) RecurrenceID() *graphql.Time {
+	if resolver.meeting.RecurrenceID == nil {
+		return nil
+	}
+	return optionalTime(*resolver.meeting.RecurrenceID)
+}
+
+func (resolver *meetingResolver) Reminders() []int32 {
+	reminders := []int32{}
+	for _, reminder := range resolver.meeting.Reminders {
+		reminders = append(reminders, int32(reminder))
+	}
+	return reminders
+}
+
+/*
+	type overrideResolver - resolves an Override
+	@attributes:
+		override - the override
+*/
+
+type overrideResolver struct {
+	override Override
+}
+
+func (resolver *overrideResolver) RecurrenceID() graphql.Time {
+	return graphql.Time{Time: resolver.override.RecurrenceID}
+}
+
+func (resolver *overrideResolver) Title() *string {
+	return optionalString(resolver.override.Title)
+}
+
+func (resolver *overrideResolver) StartTime() graphql.Time {
+	return graphql.Time{Time: resolver.override.StartTime}
+}
+
+func (resolver *overrideResolver) EndTime() graphql.Time {
+	return graphql.Time{Time: resolver.override.EndTime}
+}
+
+/*
+	function optionalTime()
+	@return:
+		*graphql.Time - t, nil for the zero time
+*/
+
+func optionalTime(t time.Time) *graphql.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &graphql.Time{Time: t}
+}
+
+/*
+	function optionalString()
+	@return:
+		*string - s, nil for the empty string
+*/
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,418 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/dxmxnlord/golang-api/src/meetingpb"
+	"github.com/dxmxnlord/golang-api/src/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+/*
+	type MeetingService - the gRPC MeetingService of meetingpb/meetings.proto
+	@attributes:
+		api - Api with the store, the page sizes and the stream hub the service shares with the REST routes
+	@description:
+		Every method converts the messages and calls the same functions of meetings.go as the matching REST handler,
+		so both check and store meetings the same way
+*/
+
+type MeetingService struct {
+	meetingpb.UnimplementedMeetingServiceServer
+	api *Api
+}
+
+/*
+	function NewGRPCServer()
+	@params:
+		api - initialized Api
+	@return:
+		*grpc.Server - server with the MeetingService of api registered, started by `Api.Run()`
+*/
+
+func NewGRPCServer(api *Api) *grpc.Server {
+	server := grpc.NewServer()
+	meetingpb.RegisterMeetingServiceServer(server, &MeetingService{api: api})
+	return server
+}
+
+/*
+	function MeetingService.CreateMeeting()
+	@params:
+		request - the meeting to create, without id
+	@description:
+		create the meeting with Meeting.createMeeting() like POST /api/meetings
+	@return:
+		*meetingpb.Meeting - the created meeting with its id
+		error - nil | status error, see meetingStatus()
+*/
+
+func (service *MeetingService) CreateMeeting(ctx context.Context, request *meetingpb.CreateMeetingRequest) (*meetingpb.Meeting, error) {
+	if request.GetMeeting() == nil {
+		return nil, status.Error(codes.InvalidArgument, "Missing meeting")
+	}
+	meeting := meetingFromProto(request.GetMeeting())
+	meeting.ID = primitive.NilObjectID
+
+	id, err := meeting.createMeeting(service.api.Store)
+	if err != nil {
+		return nil, meetingStatus(err)
+	}
+	meeting.ID = id
+	return meetingToProto(&meeting), nil
+}
+
+/*
+	function MeetingService.GetMeeting()
+	@params:
+		request - id of the meeting
+	@return:
+		*meetingpb.Meeting - the meeting
+		error - nil | status error
+*/
+
+func (service *MeetingService) GetMeeting(ctx context.Context, request *meetingpb.GetMeetingRequest) (*meetingpb.Meeting, error) {
+	id, err := primitive.ObjectIDFromHex(request.GetId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "Invalid id")
+	}
+
+	meeting := Meeting{Meeting: model.Meeting{ID: id}}
+	if err := meeting.getMeeting(service.api.Store); err == ErrMeetingNotFound {
+		return nil, meetingStatus(err)
+	} else if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return meetingToProto(&meeting), nil
+}
+
+/*
+	function MeetingService.ListMeetingsByRange()
+	@params:
+		request - time range, page and whether cancelled meetings are included
+	@description:
+		list the meetings and occurrences starting and ending in the range with getMeetings() like GET /api/meetings?start=&end=
+	@return:
+		*meetingpb.ListMeetingsResponse - one page of meetings
+		error - nil | status error
+*/
+
+func (service *MeetingService) ListMeetingsByRange(ctx context.Context, request *meetingpb.ListMeetingsByRangeRequest) (*meetingpb.ListMeetingsResponse, error) {
+	if request.GetStart() == nil || request.GetEnd() == nil {
+		return nil, status.Error(codes.InvalidArgument, "Missing starting or Ending time")
+	}
+	paging, err := service.paging(request.GetPageSize(), request.GetPageToken())
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := getMeetings(service.api.Store, request.GetStart().AsTime(), request.GetEnd().AsTime(), paging, request.GetIncludeCancelled())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return pageToProto(result), nil
+}
+
+/*
+	function MeetingService.ListMeetingsByParticipant()
+	@params:
+		request - participant email, page and whether cancelled meetings are included
+	@description:
+		list the meetings of the participant with getMeetingsParticipant() like GET /api/meetings?email=
+	@return:
+		*meetingpb.ListMeetingsResponse - one page of meetings
+		error - nil | status error
+*/
+
+func (service *MeetingService) ListMeetingsByParticipant(ctx context.Context, request *meetingpb.ListMeetingsByParticipantRequest) (*meetingpb.ListMeetingsResponse, error) {
+	if request.GetEmail() == "" {
+		return nil, status.Error(codes.InvalidArgument, "Missing email parameter")
+	}
+	paging, err := service.paging(request.GetPageSize(), request.GetPageToken())
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := getMeetingsParticipant(service.api.Store, request.GetEmail(), paging, request.GetIncludeCancelled())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return pageToProto(result), nil
+}
+
+/*
+	function MeetingService.WatchMeetings()
+	@params:
+		request - filter of the watched meetings: only email, only start and end, or none for all meetings
+		stream - stream to the client
+	@description:
+		subscribe to the stream hub like GET /api/meetings/stream and send every matching event until the client
+		leaves or the subscription is dropped, the client then has to watch again. Events in between are not replayed
+	@return:
+		error - nil | status error
+*/
+
+func (service *MeetingService) WatchMeetings(request *meetingpb.WatchMeetingsRequest, stream meetingpb.MeetingService_WatchMeetingsServer) error {
+	if service.api.Stream == nil {
+		return status.Error(codes.Unimplemented, "Streams are not supported by the store")
+	}
+	filter := StreamFilter{Email: request.GetEmail()}
+	if (request.GetStart() == nil) != (request.GetEnd() == nil) || (filter.Email != "" && request.GetStart() != nil) {
+		return status.Error(codes.InvalidArgument, "Invalid query parameters")
+	}
+	if request.GetStart() != nil {
+		filter.Start, filter.End = request.GetStart().AsTime(), request.GetEnd().AsTime()
+	}
+
+	events, unsubscribe, err := service.api.Stream.Subscribe()
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				return status.Error(codes.Unavailable, "Subscription dropped")
+			}
+			if !filter.matches(event) {
+				continue
+			}
+			if err := stream.Send(eventToProto(event)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+/*
+	function MeetingService.paging()
+	@params:
+		size - requested page size, 0 for Api.PageSize
+		token - next_page_token of the previous page, empty for the first page
+	@description:
+		list methods are always keyset paged, larger sizes are capped at Api.MaxPageSize like `Api.pageParams()`
+	@return:
+		Paging - page wanted by the request
+		error - nil | status error
+*/
+
+func (service *MeetingService) paging(size int32, token string) (Paging, error) {
+	paging := Paging{Size: service.api.PageSize, Keyset: true}
+	if size < 0 {
+		return paging, status.Error(codes.InvalidArgument, "Invalid page_size value")
+	}
+	if size > 0 {
+		paging.Size = int(size)
+	}
+	if paging.Size > service.api.MaxPageSize {
+		paging.Size = service.api.MaxPageSize
+	}
+
+	if token != "" {
+		after, err := parseCursor(token)
+		if err != nil {
+			return paging, status.Error(codes.InvalidArgument, err.Error())
+		}
+		paging.After = after
+	}
+	return paging, nil
+}
+
+/*
+	function meetingStatus()
+	@params:
+		err - error from a meeting function
+	@description:
+		the gRPC counterpart of meetingErrorResponse():
+			ErrMeetingNotFound, ErrParticipantNotFound - NotFound
+			*OverlapError - AlreadyExists, the slot is taken, for creates and updates alike
+			ErrMeetingChanged - Aborted, the meeting is read again and the write retried
+			any other (validation) error - InvalidArgument
+		the codes are documented on MeetingService in meetings.proto
+	@return:
+		error - status error with the message of err
+*/
+
+func meetingStatus(err error) error {
+	var overlap *OverlapError
+	if errors.As(err, &overlap) {
+		return status.Error(codes.AlreadyExists, err.Error())
+	} else if err == ErrMeetingChanged {
+		return status.Error(codes.Aborted, err.Error())
+	} else if err == ErrMeetingNotFound || err == ErrParticipantNotFound {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	return status.Error(codes.InvalidArgument, err.Error())
+}
+
+/*
+	function meetingToProto()
+	@params:
+		meeting - meeting of the store
+	@return:
+		*meetingpb.Meeting - the meeting as message, unset times are left out
+*/
+
+func meetingToProto(meeting *Meeting) *meetingpb.Meeting {
+	message := &meetingpb.Meeting{
+		Title:      meeting.Title,
+		StartTime:  timestampOf(meeting.StartTime),
+		EndTime:    timestampOf(meeting.EndTime),
+		CreatedAt:  timestampOf(meeting.CreatedAt),
+		Status:     meeting.Status,
+		Recurrence: meeting.Recurrence,
+	}
+	if !meeting.ID.IsZero() {
+		message.Id = meeting.ID.Hex()
+	}
+	for _, participant := range meeting.Participants {
+		message.Participants = append(message.Participants, &meetingpb.Participant{Email: participant.Email, Name: participant.Name, Rsvp: participant.RSVP})
+	}
+	for _, exception := range meeting.Exceptions {
+		message.Exceptions = append(message.Exceptions, timestamppb.New(exception))
+	}
+	for _, override := range meeting.Overrides {
+		message.Overrides = append(message.Overrides, &meetingpb.Override{
+			RecurrenceId: timestampOf(override.RecurrenceID),
+			Title:        override.Title,
+			StartTime:    timestampOf(override.StartTime),
+			EndTime:      timestampOf(override.EndTime),
+		})
+	}
+	if meeting.SeriesEnd != nil {
+		message.SeriesEnd = timestamppb.New(*meeting.SeriesEnd)
+	}
+	if meeting.RecurrenceID != nil {
+		message.RecurrenceId = timestamppb.New(*meeting.RecurrenceID)
+	}
+	for _, reminder := range meeting.Reminders {
+		message.Reminders = append(message.Reminders, int32(reminder))
+	}
+	return message
+}
+
+/*
+	function meetingFromProto()
+	@params:
+		message - meeting message of a request
+	@description:
+		the id is kept only if it is a valid object id, the server set fields are read too and left to the meeting
+		functions, which overwrite them like for json bodies
+	@return:
+		Meeting - the meeting
+*/
+
+func meetingFromProto(message *meetingpb.Meeting) Meeting {
+	meeting := Meeting{Meeting: model.Meeting{
+		Title:      message.GetTitle(),
+		StartTime:  timeOf(message.GetStartTime()),
+		EndTime:    timeOf(message.GetEndTime()),
+		CreatedAt:  timeOf(message.GetCreatedAt()),
+		Status:     message.GetStatus(),
+		Recurrence: message.GetRecurrence(),
+	}}
+	meeting.ID, _ = primitive.ObjectIDFromHex(message.GetId())
+	for _, participant := range message.GetParticipants() {
+		meeting.Participants = append(meeting.Participants, Participant{Email: participant.GetEmail(), Name: participant.GetName(), RSVP: participant.GetRsvp()})
+	}
+	for _, exception := range message.GetExceptions() {
+		meeting.Exceptions = append(meeting.Exceptions, timeOf(exception))
+	}
+	for _, override := range message.GetOverrides() {
+		meeting.Overrides = append(meeting.Overrides, Override{
+			RecurrenceID: timeOf(override.GetRecurrenceId()),
+			Title:        override.GetTitle(),
+			StartTime:    timeOf(override.GetStartTime()),
+			EndTime:      timeOf(override.GetEndTime()),
+		})
+	}
+	if message.GetSeriesEnd() != nil {
+		seriesEnd := timeOf(message.GetSeriesEnd())
+		meeting.SeriesEnd = &seriesEnd
+	}
+	if message.GetRecurrenceId() != nil {
+		recurrenceID := timeOf(message.GetRecurrenceId())
+		meeting.RecurrenceID = &recurrenceID
+	}
+	for _, reminder := range message.GetReminders() {
+		meeting.Reminders = append(meeting.Reminders, int(reminder))
+	}
+	return meeting
+}
+
+/*
+	function pageToProto()
+	@params:
+		page - keyset page of meetings
+	@return:
+		*meetingpb.ListMeetingsResponse - the page with the token of the next page, empty on the last page
+*/
+
+func pageToProto(page MeetingPage) *meetingpb.ListMeetingsResponse {
+	response := &meetingpb.ListMeetingsResponse{TotalSize: page.Total}
+	for i := range page.Meetings {
+		response.Meetings = append(response.Meetings, meetingToProto(&page.Meetings[i]))
+	}
+	if page.Next != nil {
+		response.NextPageToken = page.Next.String()
+	}
+	return response
+}
+
+/*
+	function eventToProto()
+	@params:
+		event - event of the stream hub
+	@return:
+		*meetingpb.MeetingEvent - the event as message
+*/
+
+func eventToProto(event Event) *meetingpb.MeetingEvent {
+	message := &meetingpb.MeetingEvent{
+		Id:        event.ID.Hex(),
+		Type:      event.Type,
+		CreatedAt: timestampOf(event.CreatedAt),
+		Meeting:   meetingToProto(&event.Meeting),
+		Email:     event.Email,
+	}
+	if event.Previous != nil {
+		message.Previous = meetingToProto(event.Previous)
+	}
+	return message
+}
+
+/*
+	function timestampOf()
+	@return:
+		*timestamppb.Timestamp - t as timestamp, nil for the zero time
+*/
+
+func timestampOf(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+/*
+	function timeOf()
+	@return:
+		time.Time - the timestamp in UTC, the zero time for nil
+*/
+
+func timeOf(timestamp *timestamppb.Timestamp) time.Time {
+	if timestamp == nil {
+		return time.Time{}
+	}
+	return timestamp.AsTime()
+}
@@ -4,19 +4,22 @@ import (
 	"encoding/json"
 	"net/http"
 	"context"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 )
 
 /*
 	function getContext()
 	@params:
-		secs - timeout seconds
+		timeout - timeout of the action
 	@description:
 		return context with a timeout for mongoDb actions
 */
 
-func getContext(secs time.Duration) context.Context {
-	ctx, _ := context.WithTimeout(context.Background(), secs*time.Second)
+func getContext(timeout time.Duration) context.Context {
+	ctx, _ := context.WithTimeout(context.Background(), timeout)
 	return ctx
 }
 
@@ -51,3 +54,114 @@ func errorResponse(w http.ResponseWriter, status int, msg string) {
 	jsonResponse(w, status, map[string]string{"error": msg})
 }
 
+/*
+	function calendarResponse()
+	@params:
+		http.ResponseWriter - response object
+		filename - name of the downloaded file, empty for an inline feed
+		meetings - meetings of the calendar
+	@description:
+		Write meetings as an iCalendar document after setting headers, with filename as attachment if given
+*/
+
+func calendarResponse(w http.ResponseWriter, filename string, meetings []Meeting) {
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	if filename != "" {
+		w.Header().Set("Content-Disposition", "attachment; filename=\""+filename+"\"")
+	}
+	w.WriteHeader(http.StatusOK)
+	writeCalendar(w, icsOptions{}, meetings)
+}
+
+/*
+	function csvResponse()
+	@params:
+		http.ResponseWriter - response object
+		filename - name of the downloaded file
+		fetch - returns the meetings of a keyset page
+	@description:
+		Fetch the first page before the headers so that a store error still gets an error response
+		Set the csv headers and stream the meetings with writeCSV(), flushing the response after every page
+		A store error after the first page can only end the response early, it is logged
+*/
+
+func csvResponse(w http.ResponseWriter, filename string, fetch func(Paging) (MeetingPage, error)) {
+	first, err := fetch(Paging{Keyset: true, Size: csvExportPageSize})
+	if err != nil {
+		errorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	pages := func(paging Paging) (MeetingPage, error) {
+		if paging.After == nil {
+			return first, nil
+		}
+		return fetch(paging)
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", "attachment; filename=\""+filename+"\"")
+	w.WriteHeader(http.StatusOK)
+
+	flush := func() {
+		if flusher, ok := w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+	}
+	if err := writeCSV(w, pages, flush); err != nil {
+		fmt.Println(err)
+	}
+}
+
+/*
+	function pageHeaders()
+	@params:
+		http.ResponseWriter - response object
+		http.Request - the list request
+		paging - page wanted by the request
+		result - page returned for the request
+	@description:
+		Set X-Total-Count for every list response
+		For keyset paged responses also set X-Page-Size, and X-Next-Cursor with a next Link unless it is the last page
+		For numbered pages also set X-Page, X-Page-Size and a Link header with the first, prev, next and last pages
+*/
+
+func pageHeaders(w http.ResponseWriter, r *http.Request, paging Paging, result MeetingPage) {
+	w.Header().Set("X-Total-Count", strconv.FormatInt(result.Total, 10))
+	if paging.Page == -1 && !paging.Keyset {
+		return
+	}
+	w.Header().Set("X-Page-Size", strconv.Itoa(paging.Size))
+
+	link := func(param, value, rel string) string {
+		query := r.URL.Query()
+		query.Set(param, value)
+		query.Set("page_size", strconv.Itoa(paging.Size))
+		return fmt.Sprintf("<%s?%s>; rel=\"%s\"", r.URL.Path, query.Encode(), rel)
+	}
+
+	if paging.Keyset {
+		if result.Next != nil {
+			w.Header().Set("X-Next-Cursor", result.Next.String())
+			w.Header().Set("Link", link("cursor", result.Next.String(), "next"))
+		}
+		return
+	}
+
+	page := paging.Page
+	w.Header().Set("X-Page", strconv.Itoa(page))
+
+	last := int((result.Total + int64(paging.Size) - 1) / int64(paging.Size))
+	if last < 1 {
+		last = 1
+	}
+
+	links := []string{link("page", "1", "first")}
+	if page > 1 {
+		links = append(links, link("page", strconv.Itoa(page-1), "prev"))
+	}
+	if page < last {
+		links = append(links, link("page", strconv.Itoa(page+1), "next"))
+	}
+	links = append(links, link("page", strconv.Itoa(last), "last"))
+	w.Header().Set("Link", strings.Join(links, ", "))
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// icsTime is the UTC date time format of iCalendar
+const icsTime = "20060102T150405Z"
+
+// partstats maps Participant.RSVP to the iCalendar PARTSTAT of an ATTENDEE
+var partstats = map[string]string{
+	"Yes":          "ACCEPTED",
+	"No":           "DECLINED",
+	"Maybe":        "TENTATIVE",
+	"Not Answered": "NEEDS-ACTION",
+}
+
+/*
+	type icsOptions - calendar wide settings of writeCalendar()
+	@attributes:
+		Method - iCalendar METHOD like REQUEST or CANCEL, empty for a plain calendar or feed
+		Organizer - email of the ORGANIZER of the events, left out if empty
+		Stamp - DTSTAMP of the events, the CreatedAt of each meeting if zero
+		Sequence - SEQUENCE of the events, left out if 0
+*/
+
+type icsOptions struct {
+	Method    string
+	Organizer string
+	Stamp     time.Time
+	Sequence  int
+}
+
+/*
+	function writeCalendar()
+	@params:
+		w - writer for the calendar
+		options - method, organizer, stamp and sequence of the events
+		meetings - meetings to write
+	@description:
+		write an RFC 5545 VCALENDAR with one VEVENT per meeting
+		a series gets its RRULE and EXDATE lines and one more VEVENT with a RECURRENCE-ID for every override
+	@return:
+		error - nil | write error
+*/
+
+func writeCalendar(w io.Writer, options icsOptions, meetings []Meeting) error {
+	var buffer bytes.Buffer
+	line := func(name, value string) {
+		writeICSLine(&buffer, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//golang-api//meetings//EN")
+	line("CALSCALE", "GREGORIAN")
+	if options.Method != "" {
+		line("METHOD", options.Method)
+	}
+
+	for _, meeting := range meetings {
+		writeEvent(&buffer, options, meeting, meeting.Title, meeting.StartTime, meeting.EndTime, nil)
+		for _, override := range meeting.Overrides {
+			title := override.Title
+			if title == "" {
+				title = meeting.Title
+			}
+			id := override.RecurrenceID
+			writeEvent(&buffer, options, meeting, title, override.StartTime, override.EndTime, &id)
+		}
+	}
+
+	line("END", "VCALENDAR")
+	_, err := w.Write(buffer.Bytes())
+	return err
+}
+
+/*
+	function writeEvent()
+	@params:
+		buffer - calendar being written
+		options - calendar wide settings
+		meeting - meeting of the event
+		title, start, end - summary and times of the event
+		recurrenceID - start of the overridden occurrence, nil for the meeting itself
+	@description:
+		write one VEVENT, the UID is the meeting id so that calendar apps update the same event
+		an occurrence from a range listing keeps its RecurrenceID
+*/
+
+func writeEvent(buffer *bytes.Buffer, options icsOptions, meeting Meeting, title string, start, end time.Time, recurrenceID *time.Time) {
+	line := func(name, value string) {
+		writeICSLine(buffer, name+":"+value)
+	}
+
+	stamp := options.Stamp
+	if stamp.IsZero() {
+		stamp = meeting.CreatedAt
+	}
+	if stamp.IsZero() {
+		stamp = time.Now()
+	}
+	if recurrenceID == nil {
+		recurrenceID = meeting.RecurrenceID
+	}
+
+	line("BEGIN", "VEVENT")
+	line("UID", meeting.ID.Hex()+"@golang-api")
+	line("DTSTAMP", stamp.UTC().Format(icsTime))
+	if options.Sequence != 0 {
+		line("SEQUENCE", strconv.Itoa(options.Sequence))
+	}
+	if recurrenceID != nil {
+		line("RECURRENCE-ID", recurrenceID.UTC().Format(icsTime))
+	}
+	line("DTSTART", start.UTC().Format(icsTime))
+	line("DTEND", end.UTC().Format(icsTime))
+	line("SUMMARY", escapeICSText(title))
+	if meeting.Status == StatusCancelled {
+		line("STATUS", "CANCELLED")
+	} else {
+		line("STATUS", "CONFIRMED")
+	}
+	if options.Organizer != "" {
+		line("ORGANIZER", "mailto:"+options.Organizer)
+	}
+
+	if recurrenceID == nil && meeting.Recurrence != "" {
+		line("RRULE", meeting.Recurrence)
+		for _, exception := range meeting.Exceptions {
+			line("EXDATE", exception.UTC().Format(icsTime))
+		}
+	}
+
+	for _, participant := range meeting.Participants {
+		partstat, ok := partstats[participant.RSVP]
+		if !ok {
+			partstat = "NEEDS-ACTION"
+		}
+		writeICSLine(buffer, "ATTENDEE;CN="+quoteICSParam(participant.Name)+";PARTSTAT="+partstat+
+			";RSVP=TRUE:mailto:"+participant.Email)
+	}
+	line("END", "VEVENT")
+}
+
+/*
+	function writeICSLine()
+	@params:
+		buffer - calendar being written
+		content - content line without line break
+	@description:
+		fold the line into chunks of at most 75 octets, continuation lines start with a space,
+		without splitting a multi byte character, and end every line with CRLF
+*/
+
+func writeICSLine(buffer *bytes.Buffer, content string) {
+	limit := 75
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(content[cut]) {
+			cut--
+		}
+		buffer.WriteString(content[:cut])
+		buffer.WriteString("\r\n ")
+		content = content[cut:]
+		limit = 74
+	}
+	buffer.WriteString(content)
+	buffer.WriteString("\r\n")
+}
+
+/*
+	function isRuneStart()
+	@return:
+		bool - true if b is not a continuation byte of a utf-8 character
+*/
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+/*
+	function escapeICSText()
+	@return:
+		string - text with backslashes, semicolons, commas and line breaks (CRLF, LF or a lone CR) escaped
+			for a TEXT value, other control characters but tabs are dropped so they can not end the line
+*/
+
+func escapeICSText(text string) string {
+	text = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`).Replace(text)
+	return stripControl(text, "\t")
+}
+
+/*
+	function quoteICSParam()
+	@return:
+		string - parameter value quoted, double quotes and control characters are not allowed inside and are dropped
+*/
+
+func quoteICSParam(value string) string {
+	return `"` + stripControl(strings.ReplaceAll(value, `"`, ""), "") + `"`
+}
+
+/*
+	function stripControl()
+	@params:
+		text - value written to the calendar
+		keep - control characters to keep
+	@return:
+		string - text without the other control characters
+*/
+
+func stripControl(text, keep string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) && !strings.ContainsRune(keep, r) {
+			return -1
+		}
+		return r
+	}, text)
+}
@@ -0,0 +1,449 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxImportBytes limits the size of one calendar or csv upload
+const maxImportBytes = 10 << 20
+
+// rsvps maps the iCalendar PARTSTAT of an ATTENDEE to Participant.RSVP, other values are Not Answered
+var rsvps = map[string]string{
+	"ACCEPTED":  "Yes",
+	"DECLINED":  "No",
+	"TENTATIVE": "Maybe",
+}
+
+/*
+	type ImportResult - outcome of importing one event
+	@attributes:
+		UID - UID of the event
+		Title - summary of the event
+		InsertedID - id of the created meeting, missing if the import failed
+		Error - reason the import failed, empty on success
+*/
+
+type ImportResult struct {
+	UID        string              `json:"uid"`
+	Title      string              `json:"title"`
+	InsertedID *primitive.ObjectID `json:"InsertedID,omitempty"`
+	Error      string              `json:"error,omitempty"`
+}
+
+/*
+	type icsLine - one unfolded content line
+	@attributes:
+		Name - property name in upper case
+		Params - parameters with upper case names
+		Value - raw value
+*/
+
+type icsLine struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+/*
+	type icsEvent - a VEVENT converted to a meeting
+	@attributes:
+		UID - UID of the event
+		RecurrenceID - set if the event changes one occurrence of the series with the same UID
+		Meeting - the converted meeting
+		Err - reason the event could not be converted
+*/
+
+type icsEvent struct {
+	UID          string
+	RecurrenceID *time.Time
+	Meeting      Meeting
+	Err          error
+}
+
+/*
+	function importCalendar()
+	@purpose:
+		create meetings from an iCalendar document
+	@params:
+		store - MeetingStore instance
+		r - reader of the document
+	@description:
+		read the VEVENTs of the document and convert them with eventFromLines()
+		events with a RECURRENCE-ID become overrides of the series with the same UID, or exceptions if they are cancelled
+		every series or single meeting is created with Meeting.createMeeting(), one after the other,
+		so imported events run through the same validation and overlap checks as the Api
+	@return:
+		[]ImportResult - one result per VEVENT in document order, changed occurrences share the result of their series
+		error - nil | error if the document is not an iCalendar document
+*/
+
+func importCalendar(store MeetingStore, r io.Reader) ([]ImportResult, error) {
+	lines, err := readICSLines(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 || lines[0].Name != "BEGIN" || strings.ToUpper(lines[0].Value) != "VCALENDAR" {
+		return nil, errors.New("Invalid calendar")
+	}
+
+	var events []icsEvent
+	var current []icsLine
+	depth := 0
+	for _, line := range lines {
+		value := strings.ToUpper(line.Value)
+		switch {
+		case line.Name == "BEGIN" && value == "VEVENT" && depth == 0:
+			current, depth = []icsLine{}, 1
+		case line.Name == "BEGIN" && depth > 0:
+			// components inside an event like VALARM are ignored
+			depth++
+		case line.Name == "END" && depth > 1:
+			depth--
+		case line.Name == "END" && value == "VEVENT" && depth == 1:
+			events = append(events, eventFromLines(current))
+			depth = 0
+		case depth == 1:
+			current = append(current, line)
+		}
+	}
+
+	// attach changed occurrences to their series
+	series := map[string]int{}
+	for i, event := range events {
+		if event.RecurrenceID == nil && event.UID != "" {
+			series[event.UID] = i
+		}
+	}
+	for i, event := range events {
+		if event.RecurrenceID == nil || event.Err != nil {
+			continue
+		}
+		master, ok := series[event.UID]
+		if !ok {
+			events[i].Err = errors.New("No series for recurrence id")
+			continue
+		}
+		if event.Meeting.Status == StatusCancelled {
+			events[master].Meeting.Exceptions = append(events[master].Meeting.Exceptions, *event.RecurrenceID)
+			continue
+		}
+		events[master].Meeting.Overrides = append(events[master].Meeting.Overrides, Override{
+			RecurrenceID: *event.RecurrenceID,
+			Title:        event.Meeting.Title,
+			StartTime:    event.Meeting.StartTime,
+			EndTime:      event.Meeting.EndTime,
+		})
+	}
+
+	results := make([]ImportResult, len(events))
+	for i, event := range events {
+		results[i] = ImportResult{UID: event.UID, Title: event.Meeting.Title}
+		if event.Err != nil {
+			results[i].Error = event.Err.Error()
+			continue
+		}
+		if event.RecurrenceID != nil {
+			continue
+		}
+		id, err := events[i].Meeting.createMeeting(store)
+		if err != nil {
+			results[i].Error = err.Error()
+		} else {
+			results[i].InsertedID = &id
+		}
+	}
+	for i, event := range events {
+		if event.RecurrenceID != nil && event.Err == nil {
+			master := results[series[event.UID]]
+			results[i].InsertedID, results[i].Error = master.InsertedID, master.Error
+		}
+	}
+	return results, nil
+}
+
+/*
+	function eventFromLines()
+	@params:
+		lines - properties of one VEVENT
+	@description:
+		map the properties to a Meeting:
+			SUMMARY - Title
+			DTSTART, DTEND or DURATION - StartTime and EndTime in UTC, a date without DTEND lasts one day
+				a series starting at a TZID time is rejected if its occurrences in that zone would not be
+				the ones of the rule in UTC, because the zone changes its UTC offset (daylight saving time)
+			STATUS:CANCELLED - cancelled Status
+			RRULE, EXDATE - Recurrence and Exceptions
+			ATTENDEE - a Participant named after CN, with RSVP mapped from PARTSTAT
+	@return:
+		icsEvent - converted event, Err is set if a property is invalid
+*/
+
+func eventFromLines(lines []icsLine) icsEvent {
+	var event icsEvent
+	var zone string
+	var end *time.Time
+	var duration time.Duration
+	allDay := false
+	fail := func(err error) icsEvent {
+		event.Err = err
+		return event
+	}
+
+	for _, line := range lines {
+		switch line.Name {
+		case "UID":
+			event.UID = line.Value
+		case "SUMMARY":
+			event.Meeting.Title = unescapeICSText(line.Value)
+		case "DTSTART":
+			start, err := parseICSTime(line, line.Value)
+			if err != nil {
+				return fail(errors.New("Invalid DTSTART " + line.Value))
+			}
+			event.Meeting.StartTime = start
+			zone = line.Params["TZID"]
+			allDay = line.Params["VALUE"] == "DATE" || len(line.Value) == 8
+		case "DTEND":
+			t, err := parseICSTime(line, line.Value)
+			if err != nil {
+				return fail(errors.New("Invalid DTEND " + line.Value))
+			}
+			end = &t
+		case "DURATION":
+			d, err := parseICSDuration(line.Value)
+			if err != nil {
+				return fail(err)
+			}
+			duration = d
+		case "RECURRENCE-ID":
+			t, err := parseICSTime(line, line.Value)
+			if err != nil {
+				return fail(errors.New("Invalid RECURRENCE-ID " + line.Value))
+			}
+			event.RecurrenceID = &t
+		case "STATUS":
+			if strings.ToUpper(line.Value) == "CANCELLED" {
+				event.Meeting.Status = StatusCancelled
+			}
+		case "RRULE":
+			event.Meeting.Recurrence = line.Value
+		case "EXDATE":
+			for _, value := range strings.Split(line.Value, ",") {
+				t, err := parseICSTime(line, value)
+				if err != nil {
+					return fail(errors.New("Invalid EXDATE " + value))
+				}
+				event.Meeting.Exceptions = append(event.Meeting.Exceptions, t)
+			}
+		case "ATTENDEE":
+			participant := Participant{Email: line.Value, RSVP: "Not Answered"}
+			if strings.HasPrefix(strings.ToLower(participant.Email), "mailto:") {
+				participant.Email = participant.Email[len("mailto:"):]
+			}
+			participant.Name = line.Params["CN"]
+			if participant.Name == "" {
+				participant.Name = participant.Email
+			}
+			if rsvp, ok := rsvps[strings.ToUpper(line.Params["PARTSTAT"])]; ok {
+				participant.RSVP = rsvp
+			}
+			event.Meeting.Participants = append(event.Meeting.Participants, participant)
+		}
+	}
+
+	if event.Meeting.StartTime.IsZero() {
+		return fail(errors.New("Missing DTSTART"))
+	}
+	if zone != "" && event.Meeting.Recurrence != "" && driftsIn(zone, event.Meeting.Recurrence, event.Meeting.StartTime) {
+		return fail(errors.New("RRULE in time zone " + zone + " has other occurrences than in UTC, series are only supported in UTC"))
+	}
+	switch {
+	case end != nil:
+		event.Meeting.EndTime = *end
+	case duration != 0:
+		event.Meeting.EndTime = event.Meeting.StartTime.Add(duration)
+	case allDay:
+		event.Meeting.EndTime = event.Meeting.StartTime.AddDate(0, 0, 1)
+	default:
+		event.Meeting.EndTime = event.Meeting.StartTime
+	}
+	return event
+}
+
+/*
+	function readICSLines()
+	@params:
+		r - reader of an iCalendar document
+	@description:
+		unfold continuation lines, which start with a space or tab, and split each content line into
+		name, parameters and value. Parameter values may be quoted and contain ':' or ';'
+	@return:
+		[]icsLine - content lines
+		error - nil | error
+*/
+
+func readICSLines(r io.Reader) ([]icsLine, error) {
+	var unfolded []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxImportBytes)
+	for scanner.Scan() {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(text, " ") || strings.HasPrefix(text, "\t")) && len(unfolded) > 0 {
+			unfolded[len(unfolded)-1] += text[1:]
+		} else if text != "" {
+			unfolded = append(unfolded, text)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	lines := make([]icsLine, 0, len(unfolded))
+	for _, text := range unfolded {
+		var parts []string
+		quoted, begin, value := false, 0, -1
+		for i := 0; i < len(text) && value < 0; i++ {
+			switch text[i] {
+			case '"':
+				quoted = !quoted
+			case ';':
+				if !quoted {
+					parts, begin = append(parts, text[begin:i]), i+1
+				}
+			case ':':
+				if !quoted {
+					parts, value = append(parts, text[begin:i]), i+1
+				}
+			}
+		}
+		if value < 0 || parts[0] == "" {
+			return nil, errors.New("Invalid calendar line " + text)
+		}
+
+		line := icsLine{Name: strings.ToUpper(parts[0]), Params: map[string]string{}, Value: text[value:]}
+		for _, param := range parts[1:] {
+			pair := strings.SplitN(param, "=", 2)
+			if len(pair) == 2 {
+				line.Params[strings.ToUpper(pair[0])] = strings.Trim(pair[1], `"`)
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+/*
+	function parseICSTime()
+	@params:
+		line - property of the value, its TZID parameter names the IANA time zone of local times
+		value - UTC date time (20060102T150405Z), local date time (20060102T150405) or date (20060102)
+	@return:
+		time.Time - parsed time in UTC, local times without TZID are taken as UTC
+		error - nil | error
+*/
+
+func parseICSTime(line icsLine, value string) (time.Time, error) {
+	location := time.UTC
+	if tzid := line.Params["TZID"]; tzid != "" {
+		var err error
+		if location, err = time.LoadLocation(tzid); err != nil {
+			return time.Time{}, err
+		}
+	}
+
+	for _, layout := range []string{icsTime, "20060102T150405", "20060102"} {
+		if t, err := time.ParseInLocation(layout, value, location); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, errors.New("Invalid time " + value)
+}
+
+/*
+	function driftsIn()
+	@params:
+		zone - IANA time zone of the DTSTART of a series
+		recurrence - RRULE of the series
+		dtstart - start of the series
+	@description:
+		the series is stored with UTC times and expanded in UTC, so compare the occurrences of the rule in zone with
+		those in UTC until the series ends or recurrenceHorizon passed. A zone with daylight saving time moves the UTC
+		time of the occurrences in zone twice a year, and BYDAY can fall on other days when the UTC date is another one
+	@return:
+		bool - true if the occurrences differ, an invalid rule is left to Meeting.validate()
+*/
+
+func driftsIn(zone, recurrence string, dtstart time.Time) bool {
+	location, err := time.LoadLocation(zone)
+	rule, ruleErr := parseRRule(recurrence)
+	if err != nil || ruleErr != nil {
+		return false
+	}
+
+	var local, utc []time.Time
+	bound := dtstart.Add(recurrenceHorizon)
+	rule.each(dtstart.In(location), bound, func(start time.Time) bool {
+		local = append(local, start)
+		return true
+	})
+	rule.each(dtstart.UTC(), bound, func(start time.Time) bool {
+		utc = append(utc, start)
+		return true
+	})
+	if len(local) != len(utc) {
+		return true
+	}
+	for i := range local {
+		if !local[i].Equal(utc[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// icsDuration matches the dur-value of RFC 5545, like PT1H30M or P1D
+var icsDuration = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+/*
+	function parseICSDuration()
+	@params:
+		value - DURATION value
+	@return:
+		time.Duration - parsed duration, days are 24 hours
+		error - nil | error
+*/
+
+func parseICSDuration(value string) (time.Duration, error) {
+	match := icsDuration.FindStringSubmatch(value)
+	if match == nil || match[1] == "-" {
+		return 0, errors.New("Invalid DURATION " + value)
+	}
+
+	var duration time.Duration
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+	for i, unit := range units {
+		if match[i+2] != "" {
+			n, _ := strconv.Atoi(match[i+2])
+			duration += time.Duration(n) * unit
+		}
+	}
+	return duration, nil
+}
+
+/*
+	function unescapeICSText()
+	@return:
+		string - TEXT value with the escapes of escapeICSText() resolved
+*/
+
+func unescapeICSText(text string) string {
+	return strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n").Replace(text)
+}
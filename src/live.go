@@ -0,0 +1,348 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// liveHeartbeat is the wait between two heartbeats of a live calendar connection
+const liveHeartbeat = 15 * time.Second
+
+// liveTimeout is the time a live calendar connection may go without any frame from the client
+const liveTimeout = 3 * liveHeartbeat
+
+// liveWriteWait is the time writing one frame to a live calendar connection may take
+const liveWriteWait = 10 * time.Second
+
+// maxLiveMessage is the largest request accepted from a client
+const maxLiveMessage = 64 * 1024
+
+// maxLiveEmails limits the calendars one live connection can subscribe to
+const maxLiveEmails = 50
+
+/*
+	type LiveRequest - a message of the client of a live calendar connection
+	@attributes:
+		Type - subscribe or unsubscribe
+		Emails - participants whose calendars are added or removed
+		Token - token of the last message the client got before it reconnected, only read by the first subscribe
+*/
+
+type LiveRequest struct {
+	Type   string   `json:"type"`
+	Emails []string `json:"emails"`
+	Token  string   `json:"token,omitempty"`
+}
+
+/*
+	type LiveMessage - a message of the server on a live calendar connection
+	@attributes:
+		Type - subscribed, diff, heartbeat or error
+		Token - position in the event feed, reconnecting clients resume after it
+		Emails - for subscribed the subscribed calendars, for diff the subscribed calendars the meeting is on
+			(upsert) or was on (remove)
+		Event - type of the event that changed the meeting, for diff only
+		Op - upsert if the meeting is now on Emails, remove if it is no longer on any subscribed calendar
+		Meeting - the meeting as written, for diff only
+		Error - reason of an error
+*/
+
+type LiveMessage struct {
+	Type    string   `json:"type"`
+	Token   string   `json:"token,omitempty"`
+	Emails  []string `json:"emails,omitempty"`
+	Event   string   `json:"event,omitempty"`
+	Op      string   `json:"op,omitempty"`
+	Meeting *Meeting `json:"meeting,omitempty"`
+	Error   string   `json:"error,omitempty"`
+}
+
+/*
+	type liveSession - state of one live calendar connection
+	@attributes:
+		socket - the connection
+		feed - event feed of the store
+		emails - subscribed calendars
+		token - token of the last event of the feed seen by the session
+		events - the watched feed, nil before the first subscribe
+		stop - closing it ends the watched feed
+*/
+
+type liveSession struct {
+	socket *websocket.Conn
+	feed   EventFeed
+	emails map[string]bool
+	token  string
+	events <-chan FeedEvent
+	stop   chan struct{}
+}
+
+/*
+	function sameOrigin()
+	@params:
+		host - public host of the api, the Host of the request if empty
+		r - websocket handshake
+	@description:
+		browsers let every page open a websocket, with the cookies of the site, and only tell the server the Origin
+		of the page. Clients other than browsers send no Origin
+	@return:
+		bool - true if the request has no Origin or the host of its Origin is host
+*/
+
+func sameOrigin(host string, r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	parsed, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	if host == "" {
+		host = r.Host
+	}
+	return strings.EqualFold(parsed.Host, host)
+}
+
+/*
+	function newLiveSession()
+	@params:
+		socket - the connection
+		feed - event feed of the store
+	@description:
+		limit the size of the requests and move the read deadline on with every ping and pong of the client,
+		ReadMessage() moves it on with every message
+	@return:
+		*liveSession - session without subscriptions
+*/
+
+func newLiveSession(socket *websocket.Conn, feed EventFeed) *liveSession {
+	socket.SetReadLimit(maxLiveMessage)
+	socket.SetReadDeadline(time.Now().Add(liveTimeout))
+	socket.SetPongHandler(func(string) error {
+		return socket.SetReadDeadline(time.Now().Add(liveTimeout))
+	})
+	socket.SetPingHandler(func(data string) error {
+		socket.SetReadDeadline(time.Now().Add(liveTimeout))
+		err := socket.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(liveWriteWait))
+		if err == websocket.ErrCloseSent {
+			return nil
+		}
+		return err
+	})
+	return &liveSession{socket: socket, feed: feed, emails: map[string]bool{}, stop: make(chan struct{})}
+}
+
+/*
+	function liveSession.run()
+	@description:
+		read the requests of the client in a goroutine and serve the connection until either side closes it:
+			handle every request
+			send a diff for every event of the feed changing a meeting on a subscribed calendar
+			ping the client and send a heartbeat with the current token every liveHeartbeat
+		the connection is closed if the client is silent for liveTimeout, pings and pongs count
+*/
+
+func (session *liveSession) run() {
+	defer close(session.stop)
+
+	requests := make(chan LiveRequest)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		defer close(requests)
+		for {
+			_, data, err := session.socket.ReadMessage()
+			if err != nil {
+				return
+			}
+			session.socket.SetReadDeadline(time.Now().Add(liveTimeout))
+			var request LiveRequest
+			if err := json.Unmarshal(data, &request); err != nil {
+				request = LiveRequest{Type: "invalid"}
+			}
+			select {
+			case requests <- request:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	heartbeat := time.NewTicker(liveHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case request, ok := <-requests:
+			if !ok || !session.handle(request) {
+				session.close()
+				return
+			}
+		case event, ok := <-session.events:
+			if !ok {
+				session.send(LiveMessage{Type: "error", Error: "Feed closed", Token: session.token})
+				session.close()
+				return
+			}
+			session.token = event.Token
+			if message, ok := liveDiff(event.Event, session.emails); ok {
+				message.Token = session.token
+				session.send(message)
+			}
+		case <-heartbeat.C:
+			session.socket.WriteControl(websocket.PingMessage, nil, time.Now().Add(liveWriteWait))
+			session.send(LiveMessage{Type: "heartbeat", Token: session.token})
+		}
+	}
+}
+
+/*
+	function liveSession.handle()
+	@params:
+		request - request of the client
+	@description:
+		subscribe adds calendars and starts the feed on the first call, resuming after Token if it is given
+		unsubscribe removes calendars
+		both answer with the subscribed calendars and the current token, other requests with an error
+	@return:
+		bool - false if the connection has to be closed, because the token expired or the feed failed
+*/
+
+func (session *liveSession) handle(request LiveRequest) bool {
+	if request.Type != "subscribe" && request.Type != "unsubscribe" {
+		session.send(LiveMessage{Type: "error", Error: "Unknown request type"})
+		return true
+	}
+	if len(request.Emails) == 0 {
+		session.send(LiveMessage{Type: "error", Error: "Missing emails"})
+		return true
+	}
+
+	if request.Type == "unsubscribe" {
+		for _, email := range request.Emails {
+			delete(session.emails, email)
+		}
+	} else {
+		if len(session.emails)+len(request.Emails) > maxLiveEmails {
+			session.send(LiveMessage{Type: "error", Error: "Too many emails"})
+			return true
+		}
+		for _, email := range request.Emails {
+			session.emails[email] = true
+		}
+
+		if session.events == nil {
+			events, token, err := session.feed.WatchEvents(request.Token, session.stop)
+			if err != nil {
+				session.send(LiveMessage{Type: "error", Error: err.Error()})
+				return false
+			}
+			session.events = events
+			session.token = token
+			if request.Token != "" {
+				// the events after the token are still to come
+				session.token = request.Token
+			}
+		}
+	}
+
+	emails := []string{}
+	for email := range session.emails {
+		emails = append(emails, email)
+	}
+	sort.Strings(emails)
+	session.send(LiveMessage{Type: "subscribed", Token: session.token, Emails: emails})
+	return true
+}
+
+/*
+	function liveSession.send()
+	@params:
+		message - message to the client
+	@description:
+		write the message as json text, a failed or slow write ends the reading goroutine and so the session
+*/
+
+func (session *liveSession) send(message LiveMessage) {
+	data, _ := json.Marshal(message)
+	session.socket.SetWriteDeadline(time.Now().Add(liveWriteWait))
+	if err := session.socket.WriteMessage(websocket.TextMessage, data); err != nil {
+		session.socket.Close()
+	}
+}
+
+/*
+	function liveSession.close()
+	@description:
+		send a close frame and close the connection without waiting for the answer of the client
+*/
+
+func (session *liveSession) close() {
+	message := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+	session.socket.WriteControl(websocket.CloseMessage, message, time.Now().Add(liveWriteWait))
+	session.socket.Close()
+}
+
+/*
+	function liveDiff()
+	@params:
+		event - event of the feed
+		emails - subscribed calendars
+	@description:
+		The meeting is on the subscribed calendars of its participants unless it is cancelled or deleted.
+		It was on those of the participants of the previous meeting, and of the meeting itself for cancellations and deletions.
+		Meetings now on a subscribed calendar are upserted with the calendars they are on, so clients replace the meeting
+		on all their calendars, meetings only previously on one are removed
+	@return:
+		LiveMessage - the diff
+		bool - false if the event changes no subscribed calendar
+*/
+
+func liveDiff(event Event, emails map[string]bool) (LiveMessage, bool) {
+	if !streamedType(event.Type) {
+		return LiveMessage{}, false
+	}
+
+	involved := func(meeting *Meeting, into []string) []string {
+		for _, participant := range meeting.Participants {
+			if !emails[participant.Email] {
+				continue
+			}
+			known := false
+			for _, email := range into {
+				known = known || email == participant.Email
+			}
+			if !known {
+				into = append(into, participant.Email)
+			}
+		}
+		return into
+	}
+
+	var current, previous []string
+	gone := event.Type == EventMeetingDeleted || event.Meeting.Status == StatusCancelled
+	if gone {
+		previous = involved(&event.Meeting, previous)
+	} else {
+		current = involved(&event.Meeting, current)
+	}
+	if event.Previous != nil {
+		previous = involved(event.Previous, previous)
+	}
+
+	message := LiveMessage{Type: "diff", Event: event.Type, Meeting: &event.Meeting}
+	if len(current) > 0 {
+		message.Op, message.Emails = "upsert", current
+	} else if len(previous) > 0 {
+		message.Op, message.Emails = "remove", previous
+	} else {
+		return LiveMessage{}, false
+	}
+	return message, true
+}
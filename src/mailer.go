@@ -0,0 +1,434 @@
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+/*
+	type Mailer - sends meeting invitations through an SMTP relay
+	@attributes:
+		Addr - host:port of the SMTP relay
+		From - sender address, also the ORGANIZER of the invitations
+		Auth - SMTP authentication, nil for none
+		Store - log of the sends, recipients logged for an event are skipped when the event is handed over again,
+			nil to send every time
+		Timeout - time connecting to the relay and sending one mail may take, 30 seconds by default, 0 for none
+*/
+
+type Mailer struct {
+	Addr    string
+	From    string
+	Auth    smtp.Auth
+	Store   MailStore
+	Timeout time.Duration
+}
+
+/*
+	type MailSend - a mail of an event the relay accepted or permanently rejected
+	@attributes:
+		ID - id of the send
+		EventID - event the mail was sent for
+		Email - recipient
+		Error - reply of the relay if it rejected the recipient or the message, empty if it accepted the mail
+		CreatedAt - time of the send
+*/
+
+type MailSend struct {
+	ID        primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	EventID   primitive.ObjectID `json:"event_id" bson:"event_id"`
+	Email     string             `json:"email" bson:"email"`
+	Error     string             `json:"error,omitempty" bson:"error,omitempty"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+}
+
+/*
+	function NewMailer()
+	@params:
+		addr - host:port of the SMTP relay
+		from - sender address
+		username, password - PLAIN authentication, none if username is empty
+	@return:
+		*Mailer - mailer for the relay
+*/
+
+func NewMailer(addr, from, username, password string) *Mailer {
+	mailer := &Mailer{Addr: addr, From: from, Timeout: 30 * time.Second}
+	if username != "" {
+		host := addr
+		if i := strings.LastIndex(addr, ":"); i >= 0 {
+			host = addr[:i]
+		}
+		mailer.Auth = smtp.PlainAuth("", username, password, host)
+	}
+	return mailer
+}
+
+/*
+	function Mailer.Publish()
+	@params:
+		event - event of a meeting write
+	@description:
+		for meeting.created and meeting.updated send every participant an invitation with METHOD:REQUEST,
+		and send the participants an update removed a cancellation with METHOD:CANCEL,
+		for meeting.cancelled and for meeting.deleted of a meeting that was not cancelled before
+		send every participant a cancellation with METHOD:CANCEL,
+		for meeting.reminder send a reminder to every recipient of the event, ignore other events
+	@return:
+		error - nil | last send error which is worth retrying, the outbox then hands the event over again
+*/
+
+func (mailer *Mailer) Publish(event Event) error {
+	cancelled := event.Meeting
+	cancelled.Status = StatusCancelled
+
+	switch event.Type {
+	case EventMeetingCreated, EventMeetingUpdated:
+		lastErr := mailer.invite(event, "REQUEST", event.Meeting)
+		if event.Previous == nil {
+			return lastErr
+		}
+		if cancelled.Participants = removedParticipants(*event.Previous, event.Meeting); len(cancelled.Participants) > 0 {
+			if err := mailer.invite(event, "CANCEL", cancelled); err != nil {
+				lastErr = err
+			}
+		}
+		return lastErr
+	case EventMeetingCancelled:
+		return mailer.invite(event, "CANCEL", event.Meeting)
+	case EventMeetingDeleted:
+		if event.Meeting.Status == StatusCancelled {
+			return nil
+		}
+		return mailer.invite(event, "CANCEL", cancelled)
+	case EventMeetingReminder:
+		return mailer.remind(event)
+	}
+	return nil
+}
+
+/*
+	function Mailer.invite()
+	@params:
+		event - event of the meeting write
+		method - REQUEST or CANCEL
+		meeting - meeting of the calendar, its participants are the recipients
+	@description:
+		the SEQUENCE of the calendar is the number of seconds between the creation of the meeting and the event,
+		so calendar apps apply later updates over earlier ones
+	@return:
+		error - nil | last send error which is worth retrying
+*/
+
+func (mailer *Mailer) invite(event Event, method string, meeting Meeting) error {
+	options := icsOptions{
+		Method:    method,
+		Organizer: mailer.From,
+		Stamp:     event.CreatedAt,
+		Sequence:  int(event.CreatedAt.Sub(meeting.CreatedAt) / time.Second),
+	}
+	var calendar bytes.Buffer
+	if err := writeCalendar(&calendar, options, []Meeting{meeting}); err != nil {
+		return err
+	}
+
+	var lastErr error
+	for _, participant := range meeting.Participants {
+		message, err := mailer.invitation(event, participant, method, calendar.Bytes())
+		if err == nil {
+			err = mailer.deliver(event, participant.Email, message)
+		}
+		if err != nil {
+			fmt.Println(err)
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+/*
+	function removedParticipants()
+	@params:
+		previous - meeting before the update
+		meeting - meeting after the update
+	@return:
+		[]Participant - participants of previous whose email is not a participant of meeting
+*/
+
+func removedParticipants(previous, meeting Meeting) []Participant {
+	kept := map[string]bool{}
+	for _, participant := range meeting.Participants {
+		kept[participant.Email] = true
+	}
+	var removed []Participant
+	for _, participant := range previous.Participants {
+		if !kept[participant.Email] {
+			removed = append(removed, participant)
+		}
+	}
+	return removed
+}
+
+/*
+	function Mailer.remind()
+	@params:
+		event - meeting.reminder event
+	@description:
+		send every recipient of the event a plain text reminder of the occurrence
+	@return:
+		error - nil | last send error which is worth retrying
+*/
+
+func (mailer *Mailer) remind(event Event) error {
+	meeting := event.Meeting
+	text := meeting.Title + " starts now"
+	if event.Minutes > 0 {
+		text = fmt.Sprintf("%s starts in %d minutes", meeting.Title, event.Minutes)
+	}
+	text += "\r\n\r\nStart: " + meeting.StartTime.UTC().Format(time.RFC1123) +
+		"\r\nEnd: " + meeting.EndTime.UTC().Format(time.RFC1123) + "\r\n"
+
+	var lastErr error
+	for _, email := range event.Recipients {
+		var message bytes.Buffer
+		message.WriteString("From: " + mailer.From + "\r\n")
+		message.WriteString("To: " + email + "\r\n")
+		message.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", "Reminder: "+meeting.Title) + "\r\n")
+		message.WriteString("Date: " + event.CreatedAt.Format(time.RFC1123Z) + "\r\n")
+		message.WriteString("Message-ID: <" + event.ID.Hex() + "." + email + ">\r\n")
+		message.WriteString("MIME-Version: 1.0\r\n")
+		message.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+		message.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+		writeBase64(&message, []byte(text))
+
+		if err := mailer.deliver(event, email, message.Bytes()); err != nil {
+			fmt.Println(err)
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+/*
+	function Mailer.deliver()
+	@params:
+		event - event the message is sent for
+		email - recipient
+		message - the message
+	@description:
+		skip the recipient if the send log already has the event for it, else send the message and log the send
+		once the relay accepted it or rejected it for good, so the recipient gets it once and a rejected recipient
+		does not keep the event in the outbox
+	@return:
+		error - nil | error of a send which is worth retrying | error of the send log
+*/
+
+func (mailer *Mailer) deliver(event Event, email string, message []byte) error {
+	if mailer.Store != nil {
+		if sent, err := mailer.Store.Sent(event.ID, email); err != nil || sent {
+			return err
+		}
+	}
+
+	rejection, err := mailer.send(email, message)
+	if err != nil {
+		return err
+	}
+	if rejection != "" {
+		fmt.Println("Giving up on " + email + ": " + rejection)
+	}
+	if mailer.Store == nil {
+		return nil
+	}
+	return mailer.Store.InsertSend(&MailSend{EventID: event.ID, Email: email, Error: rejection, CreatedAt: time.Now()})
+}
+
+/*
+	function Mailer.send()
+	@params:
+		to - recipient
+		message - the message
+	@description:
+		send the message like smtp.SendMail, using STARTTLS and the authentication if the relay supports them,
+		but tell a permanent (5xx) rejection of the recipient or the message apart from the other errors
+		the whole send has to finish within Timeout, so a hanging relay does not block the outbox
+	@return:
+		string - reply of the relay if it rejected the recipient or the message for good, empty if it accepted the message
+		error - nil | error which is worth retrying
+*/
+
+func (mailer *Mailer) send(to string, message []byte) (string, error) {
+	host, _, err := net.SplitHostPort(mailer.Addr)
+	if err != nil {
+		return "", err
+	}
+	conn, err := net.DialTimeout("tcp", mailer.Addr, mailer.Timeout)
+	if err != nil {
+		return "", err
+	}
+	if mailer.Timeout > 0 {
+		conn.SetDeadline(time.Now().Add(mailer.Timeout))
+	}
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return "", err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err = client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return "", err
+		}
+	}
+	if mailer.Auth != nil {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return "", errors.New("smtp: server doesn't support AUTH")
+		}
+		if err = client.Auth(mailer.Auth); err != nil {
+			return "", err
+		}
+	}
+	if err = client.Mail(mailer.From); err != nil {
+		return "", err
+	}
+	if err = client.Rcpt(to); err != nil {
+		return rejected(err)
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return rejected(err)
+	}
+	if _, err = writer.Write(message); err != nil {
+		return "", err
+	}
+	if err = writer.Close(); err != nil {
+		return rejected(err)
+	}
+	// the relay accepted the message, a failing QUIT does not change that
+	client.Quit()
+	return "", nil
+}
+
+/*
+	function rejected()
+	@params:
+		err - error of a RCPT or DATA command
+	@return:
+		string - the reply if it is a permanent (5xx) SMTP error, the command fails the same way when it is retried
+		error - nil | err if it is worth retrying
+*/
+
+func rejected(err error) (string, error) {
+	if reply, ok := err.(*textproto.Error); ok && reply.Code >= 500 {
+		return reply.Error(), nil
+	}
+	return "", err
+}
+
+/*
+	function Mailer.invitation()
+	@params:
+		event - event of the meeting write
+		participant - recipient
+		method - REQUEST or CANCEL
+		calendar - iCalendar document of the meeting
+	@description:
+		build a multipart/mixed message with a text part describing the meeting, the calendar inline as
+		text/calendar for calendar apps and the calendar again as invite.ics attachment
+		a CANCEL of meeting.updated tells a removed participant they are no longer invited
+	@return:
+		[]byte - the message
+		error - nil | error
+*/
+
+func (mailer *Mailer) invitation(event Event, participant Participant, method string, calendar []byte) ([]byte, error) {
+	meeting := event.Meeting
+	subject := "Invitation: " + meeting.Title
+	text := "You are invited to " + meeting.Title
+	if method == "CANCEL" && event.Type == EventMeetingUpdated {
+		subject = "Cancelled: " + meeting.Title
+		text = "You have been removed from " + meeting.Title
+	} else if method == "CANCEL" {
+		subject = "Cancelled: " + meeting.Title
+		text = meeting.Title + " has been cancelled"
+	} else if event.Type == EventMeetingUpdated {
+		subject = "Updated invitation: " + meeting.Title
+		text = meeting.Title + " has been updated"
+	}
+	text += "\r\n\r\nStart: " + meeting.StartTime.UTC().Format(time.RFC1123) +
+		"\r\nEnd: " + meeting.EndTime.UTC().Format(time.RFC1123) + "\r\n"
+
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+
+	var message bytes.Buffer
+	header := func(name, value string) {
+		message.WriteString(name + ": " + value + "\r\n")
+	}
+	header("From", mailer.From)
+	header("To", participant.Email)
+	header("Subject", mime.QEncoding.Encode("utf-8", subject))
+	header("Date", event.CreatedAt.Format(time.RFC1123Z))
+	header("Message-ID", "<"+event.ID.Hex()+"."+participant.Email+">")
+	header("MIME-Version", "1.0")
+	header("Content-Type", "multipart/mixed; boundary="+parts.Boundary())
+	message.WriteString("\r\n")
+
+	sections := []struct {
+		header textproto.MIMEHeader
+		data   []byte
+	}{
+		{textproto.MIMEHeader{"Content-Type": {"text/plain; charset=utf-8"}}, []byte(text)},
+		{textproto.MIMEHeader{"Content-Type": {"text/calendar; charset=utf-8; method=" + method}}, calendar},
+		{textproto.MIMEHeader{
+			"Content-Type":        {"application/ics; name=invite.ics"},
+			"Content-Disposition": {"attachment; filename=invite.ics"},
+		}, calendar},
+	}
+	for _, section := range sections {
+		section.header.Set("Content-Transfer-Encoding", "base64")
+		part, err := parts.CreatePart(section.header)
+		if err != nil {
+			return nil, err
+		}
+		writeBase64(part, section.data)
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+
+	message.Write(body.Bytes())
+	return message.Bytes(), nil
+}
+
+/*
+	function writeBase64()
+	@params:
+		w - part being written
+		data - content of the part
+	@description:
+		write data base64 encoded in lines of 76 characters
+*/
+
+func writeBase64(w io.Writer, data []byte) {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		w.Write([]byte(encoded[:76] + "\r\n"))
+		encoded = encoded[76:]
+	}
+	w.Write([]byte(encoded + "\r\n"))
+}
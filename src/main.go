@@ -1,16 +1,32 @@
 package main
 
+import (
+	"errors"
+	"flag"
+	"log"
+	"os"
+)
+
 /*
 	function main()
 
 	@purpose:
 		run application
 	@description:
+		load the config from the flags, the environment and the config file (see `Config` and `LoadConfig()`),
 		create empty Api object and call the Init and Run methods
+		-h prints every setting with its environment variable and default
 */
 
 func main() {
+	config, err := LoadConfig(os.Args[1:], os.LookupEnv, os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	} else if err != nil {
+		log.Fatal(err)
+	}
+
 	api := Api{}
-	api.Init("appointy-api")
-	api.Run(":8080")
-}
\ No newline at end of file
+	api.Init(config)
+	api.Run()
+}
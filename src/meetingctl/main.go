@@ -0,0 +1,515 @@
+// Command meetingctl inspects and changes the meetings of a running api server from a terminal.
+//
+//	meetingctl [-server url] [-output table|json] <command> [flags]
+//
+// Run meetingctl without arguments for the commands.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dxmxnlord/golang-api/src/client"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// usage lists the commands of meetingctl
+const usage = `usage: meetingctl [-server url] [-output table|json] <command> [flags]
+
+commands:
+  create  -title t -start time -end time [-participant email,name,rsvp]... [-recurrence rule] [-reminder minutes]...
+  create  -file meeting.json            (- for stdin)
+  get     <id>
+  list    -email e | -start time -end time  [-page-size n] [-cursor c] [-all] [-include-cancelled]
+  rsvp    <id> -email e -rsvp Yes|No|Maybe|"Not Answered"
+  export  <id> | -email e  [-o file.ics]
+
+times are RFC 3339, e.g. 2020-10-19T10:00:00Z. The server defaults to $MEETINGCTL_SERVER or http://localhost:8080
+`
+
+// errUsage is returned for invalid arguments, usage is printed for it
+var errUsage = errors.New("invalid arguments")
+
+/*
+	type cli - state of one meetingctl run
+	@attributes:
+		client - client of the api server
+		output - table or json
+		stdout - writer of the results
+		stdin - reader of -file -
+*/
+
+type cli struct {
+	client *client.Client
+	output string
+	stdout io.Writer
+	stdin  io.Reader
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+/*
+	function run()
+	@params:
+		args - command line arguments without the program name
+		stdin, stdout, stderr - streams of the command
+	@description:
+		parse the global flags and call the command, errors of the api are printed with their message
+	@return:
+		int - exit code: 0 on success, 1 if the command failed, 2 for invalid arguments
+*/
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("meetingctl", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	server := os.Getenv("MEETINGCTL_SERVER")
+	if server == "" {
+		server = "http://localhost:8080"
+	}
+	flags.StringVar(&server, "server", server, "")
+	output := flags.String("output", "table", "")
+	if err := flags.Parse(args); err != nil || flags.NArg() == 0 || (*output != "table" && *output != "json") {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	c := &cli{client: client.New(server), output: *output, stdout: stdout, stdin: stdin}
+	commands := map[string]func(context.Context, []string) error{
+		"create": c.create,
+		"get":    c.get,
+		"list":   c.list,
+		"rsvp":   c.rsvp,
+		"export": c.export,
+	}
+	command, ok := commands[flags.Arg(0)]
+	if !ok {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	if err := command(context.Background(), flags.Args()[1:]); err == errUsage {
+		fmt.Fprint(stderr, usage)
+		return 2
+	} else if err != nil {
+		var apiErr *client.Error
+		if errors.As(err, &apiErr) {
+			fmt.Fprintf(stderr, "meetingctl: %s (%d)\n", apiErr.Message, apiErr.StatusCode)
+		} else {
+			fmt.Fprintf(stderr, "meetingctl: %v\n", err)
+		}
+		return 1
+	}
+	return 0
+}
+
+/*
+	function parseFlags()
+	@params:
+		name - command name
+		args - arguments of the command
+		define - defines the flags of the command
+	@description:
+		flags may come before and after the positional arguments, like "get <id>" or "rsvp <id> -email e"
+	@return:
+		[]string - positional arguments
+		error - nil | errUsage
+*/
+
+func parseFlags(name string, args []string, define func(*flag.FlagSet)) ([]string, error) {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	define(flags)
+
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, errUsage
+		}
+		if flags.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+}
+
+// stringList is a repeatable string flag
+type stringList []string
+
+func (list *stringList) String() string     { return strings.Join(*list, " ") }
+func (list *stringList) Set(v string) error { *list = append(*list, v); return nil }
+
+/*
+	function cli.create()
+	@params:
+		ctx - context of the requests
+		args - flags of the meeting, or -file with a json meeting like the body of POST /api/meetings
+	@description:
+		create the meeting and print it as stored
+	@return:
+		error - nil | error
+*/
+
+func (c *cli) create(ctx context.Context, args []string) error {
+	var title, start, end, recurrence, file string
+	var participants, reminders stringList
+	positional, err := parseFlags("create", args, func(flags *flag.FlagSet) {
+		flags.StringVar(&title, "title", "", "")
+		flags.StringVar(&start, "start", "", "")
+		flags.StringVar(&end, "end", "", "")
+		flags.StringVar(&recurrence, "recurrence", "", "")
+		flags.StringVar(&file, "file", "", "")
+		flags.Var(&participants, "participant", "")
+		flags.Var(&reminders, "reminder", "")
+	})
+	if err != nil || len(positional) > 0 {
+		return errUsage
+	}
+
+	var meeting client.Meeting
+	if file != "" {
+		if meeting, err = c.readMeeting(file); err != nil {
+			return err
+		}
+	} else {
+		meeting = client.Meeting{Title: title, Recurrence: recurrence}
+		if meeting.StartTime, err = parseTime("start", start); err != nil {
+			return err
+		}
+		if meeting.EndTime, err = parseTime("end", end); err != nil {
+			return err
+		}
+		for _, participant := range participants {
+			parts := strings.SplitN(participant, ",", 3)
+			if len(parts) != 3 {
+				return fmt.Errorf("participant %q is not email,name,rsvp", participant)
+			}
+			meeting.Participants = append(meeting.Participants, client.Participant{Email: parts[0], Name: parts[1], RSVP: parts[2]})
+		}
+		for _, reminder := range reminders {
+			var minutes int
+			if _, err := fmt.Sscan(reminder, &minutes); err != nil {
+				return fmt.Errorf("reminder %q is no number of minutes", reminder)
+			}
+			meeting.Reminders = append(meeting.Reminders, minutes)
+		}
+	}
+
+	id, err := c.client.CreateMeeting(ctx, meeting)
+	if err != nil {
+		return err
+	}
+	created, err := c.client.GetMeeting(ctx, id)
+	if err != nil {
+		return err
+	}
+	return c.printMeetings([]client.Meeting{created})
+}
+
+/*
+	function cli.readMeeting()
+	@params:
+		file - path of a json meeting, - for stdin
+	@return:
+		client.Meeting - the meeting
+		error - nil | error
+*/
+
+func (c *cli) readMeeting(file string) (client.Meeting, error) {
+	var meeting client.Meeting
+	reader := c.stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return meeting, err
+		}
+		defer f.Close()
+		reader = f
+	}
+	if err := json.NewDecoder(reader).Decode(&meeting); err != nil {
+		return meeting, fmt.Errorf("invalid meeting json: %v", err)
+	}
+	return meeting, nil
+}
+
+/*
+	function cli.get()
+	@params:
+		ctx - context of the request
+		args - id of the meeting
+	@return:
+		error - nil | error
+*/
+
+func (c *cli) get(ctx context.Context, args []string) error {
+	positional, err := parseFlags("get", args, func(*flag.FlagSet) {})
+	if err != nil || len(positional) != 1 {
+		return errUsage
+	}
+	id, err := parseID(positional[0])
+	if err != nil {
+		return err
+	}
+
+	meeting, err := c.client.GetMeeting(ctx, id)
+	if err != nil {
+		return err
+	}
+	return c.printMeetings([]client.Meeting{meeting})
+}
+
+/*
+	function cli.list()
+	@params:
+		ctx - context of the requests
+		args - -email or -start and -end, and the paging flags
+	@description:
+		print one page and the cursor of the next one, or all pages with -all
+		as json a page is {"meetings", "total", "next_cursor"}, all pages are a list of meetings
+	@return:
+		error - nil | error
+*/
+
+func (c *cli) list(ctx context.Context, args []string) error {
+	var email, start, end, cursor string
+	var pageSize int
+	var all, includeCancelled bool
+	positional, err := parseFlags("list", args, func(flags *flag.FlagSet) {
+		flags.StringVar(&email, "email", "", "")
+		flags.StringVar(&start, "start", "", "")
+		flags.StringVar(&end, "end", "", "")
+		flags.StringVar(&cursor, "cursor", "", "")
+		flags.IntVar(&pageSize, "page-size", 0, "")
+		flags.BoolVar(&all, "all", false, "")
+		flags.BoolVar(&includeCancelled, "include-cancelled", false, "")
+	})
+	if err != nil || len(positional) > 0 || (email == "") == (start == "" && end == "") {
+		return errUsage
+	}
+
+	options := &client.ListOptions{PageSize: pageSize, Cursor: cursor, IncludeCancelled: includeCancelled}
+	var startTime, endTime time.Time
+	if email == "" {
+		if startTime, err = parseTime("start", start); err != nil {
+			return err
+		}
+		if endTime, err = parseTime("end", end); err != nil {
+			return err
+		}
+	}
+
+	if all {
+		var meetings *client.MeetingIterator
+		if email != "" {
+			meetings = c.client.IterateByParticipant(email, options)
+		} else {
+			meetings = c.client.IterateByRange(startTime, endTime, options)
+		}
+		listed := []client.Meeting{}
+		for meetings.Next(ctx) {
+			listed = append(listed, meetings.Meeting())
+		}
+		if err := meetings.Err(); err != nil {
+			return err
+		}
+		return c.printMeetings(listed)
+	}
+
+	var page client.Page
+	if email != "" {
+		page, err = c.client.ListByParticipant(ctx, email, options)
+	} else {
+		page, err = c.client.ListByRange(ctx, startTime, endTime, options)
+	}
+	if err != nil {
+		return err
+	}
+	if page.Meetings == nil {
+		page.Meetings = []client.Meeting{}
+	}
+
+	if c.output == "json" {
+		return c.printJSON(map[string]interface{}{"meetings": page.Meetings, "total": page.Total, "next_cursor": page.NextCursor})
+	}
+	if err := c.printMeetings(page.Meetings); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.stdout, "\n%d of %d meetings", len(page.Meetings), page.Total)
+	if page.NextCursor != "" {
+		fmt.Fprintf(c.stdout, ", next page: -cursor %s", page.NextCursor)
+	}
+	fmt.Fprintln(c.stdout)
+	return nil
+}
+
+/*
+	function cli.rsvp()
+	@params:
+		ctx - context of the request
+		args - id of the meeting, -email and -rsvp
+	@return:
+		error - nil | error
+*/
+
+func (c *cli) rsvp(ctx context.Context, args []string) error {
+	var email, rsvp string
+	positional, err := parseFlags("rsvp", args, func(flags *flag.FlagSet) {
+		flags.StringVar(&email, "email", "", "")
+		flags.StringVar(&rsvp, "rsvp", "", "")
+	})
+	if err != nil || len(positional) != 1 || email == "" || rsvp == "" {
+		return errUsage
+	}
+	id, err := parseID(positional[0])
+	if err != nil {
+		return err
+	}
+
+	meeting, err := c.client.SetRSVP(ctx, id, email, rsvp)
+	if err != nil {
+		return err
+	}
+	return c.printMeetings([]client.Meeting{meeting})
+}
+
+/*
+	function cli.export()
+	@params:
+		ctx - context of the request
+		args - id of a meeting or -email for the calendar of a participant, -o for the file
+	@description:
+		write the iCalendar document to the file, or to stdout without -o. -output does not apply
+	@return:
+		error - nil | error
+*/
+
+func (c *cli) export(ctx context.Context, args []string) error {
+	var email, file string
+	positional, err := parseFlags("export", args, func(flags *flag.FlagSet) {
+		flags.StringVar(&email, "email", "", "")
+		flags.StringVar(&file, "o", "", "")
+	})
+	if err != nil || (len(positional) == 1) == (email != "") || len(positional) > 1 {
+		return errUsage
+	}
+
+	var calendar []byte
+	if email != "" {
+		calendar, err = c.client.CalendarICS(ctx, email)
+	} else {
+		var id primitive.ObjectID
+		if id, err = parseID(positional[0]); err != nil {
+			return err
+		}
+		calendar, err = c.client.MeetingICS(ctx, id)
+	}
+	if err != nil {
+		return err
+	}
+
+	if file == "" {
+		_, err = c.stdout.Write(calendar)
+		return err
+	}
+	return os.WriteFile(file, calendar, 0644)
+}
+
+/*
+	function cli.printMeetings()
+	@params:
+		meetings - meetings to print
+	@description:
+		print the meetings as indented json, or as table with one row per meeting
+	@return:
+		error - nil | write error
+*/
+
+func (c *cli) printMeetings(meetings []client.Meeting) error {
+	if c.output == "json" {
+		if len(meetings) == 1 {
+			return c.printJSON(meetings[0])
+		}
+		return c.printJSON(meetings)
+	}
+
+	table := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "ID\tSTART\tEND\tTITLE\tSTATUS\tPARTICIPANTS")
+	for _, meeting := range meetings {
+		status := meeting.Status
+		if status == "" {
+			status = "active"
+		}
+		if meeting.Recurrence != "" && meeting.RecurrenceID == nil {
+			status += " (" + meeting.Recurrence + ")"
+		}
+		var participants []string
+		for _, participant := range meeting.Participants {
+			participants = append(participants, participant.Email+":"+participant.RSVP)
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\t%s\n", meeting.ID.Hex(), meeting.StartTime.Format(time.RFC3339),
+			meeting.EndTime.Format(time.RFC3339), meeting.Title, status, strings.Join(participants, " "))
+	}
+	return table.Flush()
+}
+
+/*
+	function cli.printJSON()
+	@params:
+		value - value to print
+	@return:
+		error - nil | write error
+*/
+
+func (c *cli) printJSON(value interface{}) error {
+	encoder := json.NewEncoder(c.stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+/*
+	function parseTime()
+	@params:
+		name - flag name, for the error
+		value - flag value
+	@return:
+		time.Time - the RFC 3339 time
+		error - nil | error if it is missing or invalid
+*/
+
+func parseTime(name, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, fmt.Errorf("missing -%s", name)
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("-%s %q is no RFC 3339 time", name, value)
+	}
+	return t.UTC(), nil
+}
+
+/*
+	function parseID()
+	@params:
+		value - meeting id argument
+	@return:
+		primitive.ObjectID - the id
+		error - nil | error if it is no object id
+*/
+
+func parseID(value string) (primitive.ObjectID, error) {
+	id, err := primitive.ObjectIDFromHex(value)
+	if err != nil {
+		return id, fmt.Errorf("%q is no meeting id", value)
+	}
+	return id, nil
+}
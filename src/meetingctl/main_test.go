@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const meetingJSON = `{"_id":"5f8d0d55b54764421b7156c9","title":"standup","start_time":"2020-10-19T10:00:00Z","end_time":"2020-10-19T11:00:00Z",
+	"participants":[{"email":"p1@gmail.com","name":"p1","rsvp":"Yes"}]}`
+
+// fakeServer answers like the api server and records the requests
+func fakeServer(t *testing.T, requests *[]string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		*requests = append(*requests, r.Method+" "+r.URL.RequestURI()+" "+string(body))
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/api/meetings":
+			w.Write([]byte(`{"InsertedID":"5f8d0d55b54764421b7156c9"}`))
+		case r.URL.Path == "/api/meeting" && r.URL.Query().Get("format") == "ics":
+			w.Write([]byte("BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n"))
+		case r.URL.Path == "/api/meeting" && r.URL.Query().Get("id") == "5f8d0d55b54764421b7156c9":
+			w.Write([]byte(meetingJSON))
+		case r.URL.Path == "/api/meeting":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"Meeting not found"}`))
+		case r.URL.Path == "/api/calendar":
+			w.Write([]byte("BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n"))
+		case r.URL.Path == "/api/meetings":
+			w.Header().Set("X-Total-Count", "3")
+			if r.URL.Query().Get("cursor") == "" {
+				w.Header().Set("X-Next-Cursor", "next")
+			}
+			w.Write([]byte("[" + meetingJSON + "]"))
+		case r.URL.Path == "/api/meetings/5f8d0d55b54764421b7156c9/rsvp":
+			w.Write([]byte(strings.Replace(meetingJSON, `"Yes"`, `"No"`, 1)))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"unexpected request"}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRun(t *testing.T) {
+	var requests []string
+	server := fakeServer(t, &requests)
+	file := filepath.Join(t.TempDir(), "meeting.json")
+	os.WriteFile(file, []byte(meetingJSON), 0644)
+
+	tests := []struct {
+		name    string
+		args    string
+		stdin   string
+		code    int
+		request string
+		stdout  string
+	}{
+		{"create from flags", "create -title standup -start 2020-10-19T10:00:00Z -end 2020-10-19T11:00:00Z -participant p1@gmail.com,p1,Yes -reminder 5", "", 0,
+			`"title":"standup","start_time":"2020-10-19T10:00:00Z","end_time":"2020-10-19T11:00:00Z","created_at":"0001-01-01T00:00:00Z","participants":[{"email":"p1@gmail.com","name":"p1","rsvp":"Yes"}],"reminders":[5]}`,
+			"5f8d0d55b54764421b7156c9  2020-10-19T10:00:00Z"},
+		{"create from file", "create -file " + file, "", 0, `POST /api/meetings {"_id":"5f8d0d55b54764421b7156c9","title":"standup"`, "standup"},
+		{"create from stdin", "-output json create -file -", meetingJSON, 0, `POST /api/meetings`, `"title": "standup"`},
+		{"create with invalid time", "create -title x -start tomorrow -end 2020-10-19T11:00:00Z", "", 1, "", ""},
+		{"get", "-output json get 5f8d0d55b54764421b7156c9", "", 0, "GET /api/meeting?id=5f8d0d55b54764421b7156c9", `"email": "p1@gmail.com"`},
+		{"get missing meeting", "get 5f8d0d55b54764421b7156ca", "", 1, "GET /api/meeting?id=5f8d0d55b54764421b7156ca", ""},
+		{"list by email", "list -email p1@gmail.com -page-size 1", "", 0, "GET /api/meetings?cursor=&email=p1%40gmail.com&page_size=1",
+			"1 of 3 meetings, next page: -cursor next"},
+		{"list by range", "-output json list -start 2020-10-19T00:00:00Z -end 2020-10-20T00:00:00Z -cursor abc", "", 0,
+			"GET /api/meetings?cursor=abc&end=2020-10-20T00%3A00%3A00Z&start=2020-10-19T00%3A00%3A00Z", `"next_cursor": ""`},
+		{"list needs email or range", "list", "", 2, "", ""},
+		{"rsvp", "rsvp 5f8d0d55b54764421b7156c9 -email p1@gmail.com -rsvp No", "", 0,
+			`PUT /api/meetings/5f8d0d55b54764421b7156c9/rsvp {"email":"p1@gmail.com","name":"","rsvp":"No"}`, "p1@gmail.com:No"},
+		{"export", "export 5f8d0d55b54764421b7156c9", "", 0, "GET /api/meeting?format=ics&id=5f8d0d55b54764421b7156c9", "BEGIN:VCALENDAR"},
+		{"unknown command", "delete 5f8d0d55b54764421b7156c9", "", 2, "", ""},
+		{"invalid output", "-output yaml get 5f8d0d55b54764421b7156c9", "", 2, "", ""},
+	}
+	for _, test := range tests {
+		requests = nil
+		var stdout, stderr bytes.Buffer
+		args := append([]string{"-server", server.URL}, strings.Fields(test.args)...)
+		code := run(args, strings.NewReader(test.stdin), &stdout, &stderr)
+		if code != test.code {
+			t.Errorf("%s: Expected exit code %d, but instead got %d: %s", test.name, test.code, code, stderr.String())
+		}
+		if test.request != "" && (len(requests) == 0 || !strings.Contains(requests[0], test.request)) {
+			t.Errorf("%s: Expected the request %q, but instead got %q", test.name, test.request, requests)
+		}
+		if !strings.Contains(stdout.String(), test.stdout) {
+			t.Errorf("%s: Expected %q in the output, but instead got %q", test.name, test.stdout, stdout.String())
+		}
+	}
+}
+
+func TestRunExportFile(t *testing.T) {
+	var requests []string
+	server := fakeServer(t, &requests)
+	file := filepath.Join(t.TempDir(), "calendar.ics")
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-server", server.URL, "export", "-email", "p1@gmail.com", "-o", file}, nil, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, but instead got %d: %s", code, stderr.String())
+	}
+	if ics, _ := os.ReadFile(file); !strings.HasPrefix(string(ics), "BEGIN:VCALENDAR") || requests[0] != "GET /api/calendar?email=p1%40gmail.com " {
+		t.Errorf("Expected the calendar in %s, but instead got %q %q", file, ics, requests)
+	}
+
+	// the list of all pages is plain json
+	stdout.Reset()
+	requests = nil
+	run([]string{"-server", server.URL, "-output", "json", "list", "-email", "p1@gmail.com", "-all"}, nil, &stdout, &stderr)
+	var meetings []map[string]interface{}
+	if err := json.Unmarshal(stdout.Bytes(), &meetings); err != nil || len(requests) < 2 {
+		t.Errorf("Expected a json list over several pages, but instead got %q %v %q", stdout.String(), err, requests)
+	}
+}
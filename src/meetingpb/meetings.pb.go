@@ -0,0 +1,916 @@
+// MeetingService mirrors the meeting routes of the REST API, see README.md.
+// Regenerate meetings.pb.go and meetings_grpc.pb.go from the src directory with
+//
+//	protoc --go_out=. --go_opt=paths=source_relative \
+//		--go-grpc_out=. --go-grpc_opt=paths=source_relative meetingpb/meetings.proto
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        v5.29.3
+// source: meetingpb/meetings.proto
+
+package meetingpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Participant struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Email string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	Name  string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// Yes, No, Maybe or Not Answered
+	Rsvp          string `protobuf:"bytes,3,opt,name=rsvp,proto3" json:"rsvp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Participant) Reset() {
+	*x = Participant{}
+	mi := &file_meetingpb_meetings_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Participant) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Participant) ProtoMessage() {}
+
+func (x *Participant) ProtoReflect() protoreflect.Message {
+	mi := &file_meetingpb_meetings_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Participant.ProtoReflect.Descriptor instead.
+func (*Participant) Descriptor() ([]byte, []int) {
+	return file_meetingpb_meetings_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Participant) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *Participant) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Participant) GetRsvp() string {
+	if x != nil {
+		return x.Rsvp
+	}
+	return ""
+}
+
+// Override changes one occurrence of a recurring meeting.
+type Override struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RecurrenceId  *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=recurrence_id,json=recurrenceId,proto3" json:"recurrence_id,omitempty"`
+	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	StartTime     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	EndTime       *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Override) Reset() {
+	*x = Override{}
+	mi := &file_meetingpb_meetings_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Override) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Override) ProtoMessage() {}
+
+func (x *Override) ProtoReflect() protoreflect.Message {
+	mi := &file_meetingpb_meetings_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Override.ProtoReflect.Descriptor instead.
+func (*Override) Descriptor() ([]byte, []int) {
+	return file_meetingpb_meetings_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Override) GetRecurrenceId() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RecurrenceId
+	}
+	return nil
+}
+
+func (x *Override) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Override) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *Override) GetEndTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndTime
+	}
+	return nil
+}
+
+type Meeting struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// hex object id, empty when creating
+	Id        string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title     string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	StartTime *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	EndTime   *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	// set by the server
+	CreatedAt    *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Participants []*Participant         `protobuf:"bytes,6,rep,name=participants,proto3" json:"participants,omitempty"`
+	// empty or cancelled
+	Status string `protobuf:"bytes,7,opt,name=status,proto3" json:"status,omitempty"`
+	// RRULE value of a recurring meeting
+	Recurrence string                   `protobuf:"bytes,8,opt,name=recurrence,proto3" json:"recurrence,omitempty"`
+	Exceptions []*timestamppb.Timestamp `protobuf:"bytes,9,rep,name=exceptions,proto3" json:"exceptions,omitempty"`
+	Overrides  []*Override              `protobuf:"bytes,10,rep,name=overrides,proto3" json:"overrides,omitempty"`
+	// set by the server for recurring meetings with an end
+	SeriesEnd *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=series_end,json=seriesEnd,proto3" json:"series_end,omitempty"`
+	// start of the occurrence as generated by the rule, for expanded occurrences only
+	RecurrenceId *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=recurrence_id,json=recurrenceId,proto3" json:"recurrence_id,omitempty"`
+	// minutes before the start
+	Reminders     []int32 `protobuf:"varint,13,rep,packed,name=reminders,proto3" json:"reminders,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Meeting) Reset() {
+	*x = Meeting{}
+	mi := &file_meetingpb_meetings_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Meeting) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Meeting) ProtoMessage() {}
+
+func (x *Meeting) ProtoReflect() protoreflect.Message {
+	mi := &file_meetingpb_meetings_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Meeting.ProtoReflect.Descriptor instead.
+func (*Meeting) Descriptor() ([]byte, []int) {
+	return file_meetingpb_meetings_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Meeting) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Meeting) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Meeting) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *Meeting) GetEndTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndTime
+	}
+	return nil
+}
+
+func (x *Meeting) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Meeting) GetParticipants() []*Participant {
+	if x != nil {
+		return x.Participants
+	}
+	return nil
+}
+
+func (x *Meeting) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Meeting) GetRecurrence() string {
+	if x != nil {
+		return x.Recurrence
+	}
+	return ""
+}
+
+func (x *Meeting) GetExceptions() []*timestamppb.Timestamp {
+	if x != nil {
+		return x.Exceptions
+	}
+	return nil
+}
+
+func (x *Meeting) GetOverrides() []*Override {
+	if x != nil {
+		return x.Overrides
+	}
+	return nil
+}
+
+func (x *Meeting) GetSeriesEnd() *timestamppb.Timestamp {
+	if x != nil {
+		return x.SeriesEnd
+	}
+	return nil
+}
+
+func (x *Meeting) GetRecurrenceId() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RecurrenceId
+	}
+	return nil
+}
+
+func (x *Meeting) GetReminders() []int32 {
+	if x != nil {
+		return x.Reminders
+	}
+	return nil
+}
+
+type CreateMeetingRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Meeting       *Meeting               `protobuf:"bytes,1,opt,name=meeting,proto3" json:"meeting,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateMeetingRequest) Reset() {
+	*x = CreateMeetingRequest{}
+	mi := &file_meetingpb_meetings_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateMeetingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateMeetingRequest) ProtoMessage() {}
+
+func (x *CreateMeetingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_meetingpb_meetings_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateMeetingRequest.ProtoReflect.Descriptor instead.
+func (*CreateMeetingRequest) Descriptor() ([]byte, []int) {
+	return file_meetingpb_meetings_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CreateMeetingRequest) GetMeeting() *Meeting {
+	if x != nil {
+		return x.Meeting
+	}
+	return nil
+}
+
+type GetMeetingRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMeetingRequest) Reset() {
+	*x = GetMeetingRequest{}
+	mi := &file_meetingpb_meetings_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMeetingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMeetingRequest) ProtoMessage() {}
+
+func (x *GetMeetingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_meetingpb_meetings_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMeetingRequest.ProtoReflect.Descriptor instead.
+func (*GetMeetingRequest) Descriptor() ([]byte, []int) {
+	return file_meetingpb_meetings_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetMeetingRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ListMeetingsByRangeRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Start *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=start,proto3" json:"start,omitempty"`
+	End   *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=end,proto3" json:"end,omitempty"`
+	// defaults to the page size of the server, capped at its max page size
+	PageSize int32 `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// next_page_token of the previous page, empty for the first page
+	PageToken        string `protobuf:"bytes,4,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	IncludeCancelled bool   `protobuf:"varint,5,opt,name=include_cancelled,json=includeCancelled,proto3" json:"include_cancelled,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ListMeetingsByRangeRequest) Reset() {
+	*x = ListMeetingsByRangeRequest{}
+	mi := &file_meetingpb_meetings_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMeetingsByRangeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMeetingsByRangeRequest) ProtoMessage() {}
+
+func (x *ListMeetingsByRangeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_meetingpb_meetings_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMeetingsByRangeRequest.ProtoReflect.Descriptor instead.
+func (*ListMeetingsByRangeRequest) Descriptor() ([]byte, []int) {
+	return file_meetingpb_meetings_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListMeetingsByRangeRequest) GetStart() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Start
+	}
+	return nil
+}
+
+func (x *ListMeetingsByRangeRequest) GetEnd() *timestamppb.Timestamp {
+	if x != nil {
+		return x.End
+	}
+	return nil
+}
+
+func (x *ListMeetingsByRangeRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListMeetingsByRangeRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *ListMeetingsByRangeRequest) GetIncludeCancelled() bool {
+	if x != nil {
+		return x.IncludeCancelled
+	}
+	return false
+}
+
+type ListMeetingsByParticipantRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Email            string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	PageSize         int32                  `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken        string                 `protobuf:"bytes,3,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	IncludeCancelled bool                   `protobuf:"varint,4,opt,name=include_cancelled,json=includeCancelled,proto3" json:"include_cancelled,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ListMeetingsByParticipantRequest) Reset() {
+	*x = ListMeetingsByParticipantRequest{}
+	mi := &file_meetingpb_meetings_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMeetingsByParticipantRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMeetingsByParticipantRequest) ProtoMessage() {}
+
+func (x *ListMeetingsByParticipantRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_meetingpb_meetings_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMeetingsByParticipantRequest.ProtoReflect.Descriptor instead.
+func (*ListMeetingsByParticipantRequest) Descriptor() ([]byte, []int) {
+	return file_meetingpb_meetings_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListMeetingsByParticipantRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *ListMeetingsByParticipantRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListMeetingsByParticipantRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *ListMeetingsByParticipantRequest) GetIncludeCancelled() bool {
+	if x != nil {
+		return x.IncludeCancelled
+	}
+	return false
+}
+
+type ListMeetingsResponse struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Meetings []*Meeting             `protobuf:"bytes,1,rep,name=meetings,proto3" json:"meetings,omitempty"`
+	// empty on the last page
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	TotalSize     int64  `protobuf:"varint,3,opt,name=total_size,json=totalSize,proto3" json:"total_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListMeetingsResponse) Reset() {
+	*x = ListMeetingsResponse{}
+	mi := &file_meetingpb_meetings_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMeetingsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMeetingsResponse) ProtoMessage() {}
+
+func (x *ListMeetingsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_meetingpb_meetings_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMeetingsResponse.ProtoReflect.Descriptor instead.
+func (*ListMeetingsResponse) Descriptor() ([]byte, []int) {
+	return file_meetingpb_meetings_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListMeetingsResponse) GetMeetings() []*Meeting {
+	if x != nil {
+		return x.Meetings
+	}
+	return nil
+}
+
+func (x *ListMeetingsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+func (x *ListMeetingsResponse) GetTotalSize() int64 {
+	if x != nil {
+		return x.TotalSize
+	}
+	return 0
+}
+
+// WatchMeetingsRequest filters the watched meetings by email, or by start and end, or not at all.
+type WatchMeetingsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	Start         *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=start,proto3" json:"start,omitempty"`
+	End           *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=end,proto3" json:"end,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchMeetingsRequest) Reset() {
+	*x = WatchMeetingsRequest{}
+	mi := &file_meetingpb_meetings_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchMeetingsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchMeetingsRequest) ProtoMessage() {}
+
+func (x *WatchMeetingsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_meetingpb_meetings_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchMeetingsRequest.ProtoReflect.Descriptor instead.
+func (*WatchMeetingsRequest) Descriptor() ([]byte, []int) {
+	return file_meetingpb_meetings_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *WatchMeetingsRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *WatchMeetingsRequest) GetStart() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Start
+	}
+	return nil
+}
+
+func (x *WatchMeetingsRequest) GetEnd() *timestamppb.Timestamp {
+	if x != nil {
+		return x.End
+	}
+	return nil
+}
+
+type MeetingEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// meeting.created, meeting.updated, meeting.cancelled, meeting.deleted or rsvp.changed
+	Type      string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	CreatedAt *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Meeting   *Meeting               `protobuf:"bytes,4,opt,name=meeting,proto3" json:"meeting,omitempty"`
+	// the meeting before the write, for writes replacing a meeting only
+	Previous *Meeting `protobuf:"bytes,5,opt,name=previous,proto3" json:"previous,omitempty"`
+	// participant whose rsvp changed, for rsvp.changed only
+	Email         string `protobuf:"bytes,6,opt,name=email,proto3" json:"email,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MeetingEvent) Reset() {
+	*x = MeetingEvent{}
+	mi := &file_meetingpb_meetings_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MeetingEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MeetingEvent) ProtoMessage() {}
+
+func (x *MeetingEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_meetingpb_meetings_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MeetingEvent.ProtoReflect.Descriptor instead.
+func (*MeetingEvent) Descriptor() ([]byte, []int) {
+	return file_meetingpb_meetings_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *MeetingEvent) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *MeetingEvent) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *MeetingEvent) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *MeetingEvent) GetMeeting() *Meeting {
+	if x != nil {
+		return x.Meeting
+	}
+	return nil
+}
+
+func (x *MeetingEvent) GetPrevious() *Meeting {
+	if x != nil {
+		return x.Previous
+	}
+	return nil
+}
+
+func (x *MeetingEvent) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+var File_meetingpb_meetings_proto protoreflect.FileDescriptor
+
+const file_meetingpb_meetings_proto_rawDesc = "" +
+	"\n" +
+	"\x18meetingpb/meetings.proto\x12\vmeetings.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"K\n" +
+	"\vParticipant\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
+	"\x04rsvp\x18\x03 \x01(\tR\x04rsvp\"\xd3\x01\n" +
+	"\bOverride\x12?\n" +
+	"\rrecurrence_id\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\frecurrenceId\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x129\n" +
+	"\n" +
+	"start_time\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
+	"\bend_time\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\aendTime\"\xdd\x04\n" +
+	"\aMeeting\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x129\n" +
+	"\n" +
+	"start_time\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
+	"\bend_time\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\aendTime\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12<\n" +
+	"\fparticipants\x18\x06 \x03(\v2\x18.meetings.v1.ParticipantR\fparticipants\x12\x16\n" +
+	"\x06status\x18\a \x01(\tR\x06status\x12\x1e\n" +
+	"\n" +
+	"recurrence\x18\b \x01(\tR\n" +
+	"recurrence\x12:\n" +
+	"\n" +
+	"exceptions\x18\t \x03(\v2\x1a.google.protobuf.TimestampR\n" +
+	"exceptions\x123\n" +
+	"\toverrides\x18\n" +
+	" \x03(\v2\x15.meetings.v1.OverrideR\toverrides\x129\n" +
+	"\n" +
+	"series_end\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\tseriesEnd\x12?\n" +
+	"\rrecurrence_id\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\frecurrenceId\x12\x1c\n" +
+	"\treminders\x18\r \x03(\x05R\treminders\"F\n" +
+	"\x14CreateMeetingRequest\x12.\n" +
+	"\ameeting\x18\x01 \x01(\v2\x14.meetings.v1.MeetingR\ameeting\"#\n" +
+	"\x11GetMeetingRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\xe5\x01\n" +
+	"\x1aListMeetingsByRangeRequest\x120\n" +
+	"\x05start\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x05start\x12,\n" +
+	"\x03end\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x03end\x12\x1b\n" +
+	"\tpage_size\x18\x03 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x04 \x01(\tR\tpageToken\x12+\n" +
+	"\x11include_cancelled\x18\x05 \x01(\bR\x10includeCancelled\"\xa1\x01\n" +
+	" ListMeetingsByParticipantRequest\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x03 \x01(\tR\tpageToken\x12+\n" +
+	"\x11include_cancelled\x18\x04 \x01(\bR\x10includeCancelled\"\x8f\x01\n" +
+	"\x14ListMeetingsResponse\x120\n" +
+	"\bmeetings\x18\x01 \x03(\v2\x14.meetings.v1.MeetingR\bmeetings\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\x12\x1d\n" +
+	"\n" +
+	"total_size\x18\x03 \x01(\x03R\ttotalSize\"\x8c\x01\n" +
+	"\x14WatchMeetingsRequest\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\x120\n" +
+	"\x05start\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x05start\x12,\n" +
+	"\x03end\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x03end\"\xe5\x01\n" +
+	"\fMeetingEvent\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x129\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12.\n" +
+	"\ameeting\x18\x04 \x01(\v2\x14.meetings.v1.MeetingR\ameeting\x120\n" +
+	"\bprevious\x18\x05 \x01(\v2\x14.meetings.v1.MeetingR\bprevious\x12\x14\n" +
+	"\x05email\x18\x06 \x01(\tR\x05email2\xc1\x03\n" +
+	"\x0eMeetingService\x12H\n" +
+	"\rCreateMeeting\x12!.meetings.v1.CreateMeetingRequest\x1a\x14.meetings.v1.Meeting\x12B\n" +
+	"\n" +
+	"GetMeeting\x12\x1e.meetings.v1.GetMeetingRequest\x1a\x14.meetings.v1.Meeting\x12a\n" +
+	"\x13ListMeetingsByRange\x12'.meetings.v1.ListMeetingsByRangeRequest\x1a!.meetings.v1.ListMeetingsResponse\x12m\n" +
+	"\x19ListMeetingsByParticipant\x12-.meetings.v1.ListMeetingsByParticipantRequest\x1a!.meetings.v1.ListMeetingsResponse\x12O\n" +
+	"\rWatchMeetings\x12!.meetings.v1.WatchMeetingsRequest\x1a\x19.meetings.v1.MeetingEvent0\x01B/Z-github.com/dxmxnlord/golang-api/src/meetingpbb\x06proto3"
+
+var (
+	file_meetingpb_meetings_proto_rawDescOnce sync.Once
+	file_meetingpb_meetings_proto_rawDescData []byte
+)
+
+func file_meetingpb_meetings_proto_rawDescGZIP() []byte {
+	file_meetingpb_meetings_proto_rawDescOnce.Do(func() {
+		file_meetingpb_meetings_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_meetingpb_meetings_proto_rawDesc), len(file_meetingpb_meetings_proto_rawDesc)))
+	})
+	return file_meetingpb_meetings_proto_rawDescData
+}
+
+var file_meetingpb_meetings_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_meetingpb_meetings_proto_goTypes = []any{
+	(*Participant)(nil),                      // 0: meetings.v1.Participant
+	(*Override)(nil),                         // 1: meetings.v1.Override
+	(*Meeting)(nil),                          // 2: meetings.v1.Meeting
+	(*CreateMeetingRequest)(nil),             // 3: meetings.v1.CreateMeetingRequest
+	(*GetMeetingRequest)(nil),                // 4: meetings.v1.GetMeetingRequest
+	(*ListMeetingsByRangeRequest)(nil),       // 5: meetings.v1.ListMeetingsByRangeRequest
+	(*ListMeetingsByParticipantRequest)(nil), // 6: meetings.v1.ListMeetingsByParticipantRequest
+	(*ListMeetingsResponse)(nil),             // 7: meetings.v1.ListMeetingsResponse
+	(*WatchMeetingsRequest)(nil),             // 8: meetings.v1.WatchMeetingsRequest
+	(*MeetingEvent)(nil),                     // 9: meetings.v1.MeetingEvent
+	(*timestamppb.Timestamp)(nil),            // 10: google.protobuf.Timestamp
+}
+var file_meetingpb_meetings_proto_depIdxs = []int32{
+	10, // 0: meetings.v1.Override.recurrence_id:type_name -> google.protobuf.Timestamp
+	10, // 1: meetings.v1.Override.start_time:type_name -> google.protobuf.Timestamp
+	10, // 2: meetings.v1.Override.end_time:type_name -> google.protobuf.Timestamp
+	10, // 3: meetings.v1.Meeting.start_time:type_name -> google.protobuf.Timestamp
+	10, // 4: meetings.v1.Meeting.end_time:type_name -> google.protobuf.Timestamp
+	10, // 5: meetings.v1.Meeting.created_at:type_name -> google.protobuf.Timestamp
+	0,  // 6: meetings.v1.Meeting.participants:type_name -> meetings.v1.Participant
+	10, // 7: meetings.v1.Meeting.exceptions:type_name -> google.protobuf.Timestamp
+	1,  // 8: meetings.v1.Meeting.overrides:type_name -> meetings.v1.Override
+	10, // 9: meetings.v1.Meeting.series_end:type_name -> google.protobuf.Timestamp
+	10, // 10: meetings.v1.Meeting.recurrence_id:type_name -> google.protobuf.Timestamp
+	2,  // 11: meetings.v1.CreateMeetingRequest.meeting:type_name -> meetings.v1.Meeting
+	10, // 12: meetings.v1.ListMeetingsByRangeRequest.start:type_name -> google.protobuf.Timestamp
+	10, // 13: meetings.v1.ListMeetingsByRangeRequest.end:type_name -> google.protobuf.Timestamp
+	2,  // 14: meetings.v1.ListMeetingsResponse.meetings:type_name -> meetings.v1.Meeting
+	10, // 15: meetings.v1.WatchMeetingsRequest.start:type_name -> google.protobuf.Timestamp
+	10, // 16: meetings.v1.WatchMeetingsRequest.end:type_name -> google.protobuf.Timestamp
+	10, // 17: meetings.v1.MeetingEvent.created_at:type_name -> google.protobuf.Timestamp
+	2,  // 18: meetings.v1.MeetingEvent.meeting:type_name -> meetings.v1.Meeting
+	2,  // 19: meetings.v1.MeetingEvent.previous:type_name -> meetings.v1.Meeting
+	3,  // 20: meetings.v1.MeetingService.CreateMeeting:input_type -> meetings.v1.CreateMeetingRequest
+	4,  // 21: meetings.v1.MeetingService.GetMeeting:input_type -> meetings.v1.GetMeetingRequest
+	5,  // 22: meetings.v1.MeetingService.ListMeetingsByRange:input_type -> meetings.v1.ListMeetingsByRangeRequest
+	6,  // 23: meetings.v1.MeetingService.ListMeetingsByParticipant:input_type -> meetings.v1.ListMeetingsByParticipantRequest
+	8,  // 24: meetings.v1.MeetingService.WatchMeetings:input_type -> meetings.v1.WatchMeetingsRequest
+	2,  // 25: meetings.v1.MeetingService.CreateMeeting:output_type -> meetings.v1.Meeting
+	2,  // 26: meetings.v1.MeetingService.GetMeeting:output_type -> meetings.v1.Meeting
+	7,  // 27: meetings.v1.MeetingService.ListMeetingsByRange:output_type -> meetings.v1.ListMeetingsResponse
+	7,  // 28: meetings.v1.MeetingService.ListMeetingsByParticipant:output_type -> meetings.v1.ListMeetingsResponse
+	9,  // 29: meetings.v1.MeetingService.WatchMeetings:output_type -> meetings.v1.MeetingEvent
+	25, // [25:30] is the sub-list for method output_type
+	20, // [20:25] is the sub-list for method input_type
+	20, // [20:20] is the sub-list for extension type_name
+	20, // [20:20] is the sub-list for extension extendee
+	0,  // [0:20] is the sub-list for field type_name
+}
+
+func init() { file_meetingpb_meetings_proto_init() }
+func file_meetingpb_meetings_proto_init() {
+	if File_meetingpb_meetings_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_meetingpb_meetings_proto_rawDesc), len(file_meetingpb_meetings_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_meetingpb_meetings_proto_goTypes,
+		DependencyIndexes: file_meetingpb_meetings_proto_depIdxs,
+		MessageInfos:      file_meetingpb_meetings_proto_msgTypes,
+	}.Build()
+	File_meetingpb_meetings_proto = out.File
+	file_meetingpb_meetings_proto_goTypes = nil
+	file_meetingpb_meetings_proto_depIdxs = nil
+}
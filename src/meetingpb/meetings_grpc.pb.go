@@ -0,0 +1,311 @@
+// MeetingService mirrors the meeting routes of the REST API, see README.md.
+// Regenerate meetings.pb.go and meetings_grpc.pb.go from the src directory with
+//
+//	protoc --go_out=. --go_opt=paths=source_relative \
+//		--go-grpc_out=. --go-grpc_opt=paths=source_relative meetingpb/meetings.proto
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: meetingpb/meetings.proto
+
+package meetingpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	MeetingService_CreateMeeting_FullMethodName             = "/meetings.v1.MeetingService/CreateMeeting"
+	MeetingService_GetMeeting_FullMethodName                = "/meetings.v1.MeetingService/GetMeeting"
+	MeetingService_ListMeetingsByRange_FullMethodName       = "/meetings.v1.MeetingService/ListMeetingsByRange"
+	MeetingService_ListMeetingsByParticipant_FullMethodName = "/meetings.v1.MeetingService/ListMeetingsByParticipant"
+	MeetingService_WatchMeetings_FullMethodName             = "/meetings.v1.MeetingService/WatchMeetings"
+)
+
+// MeetingServiceClient is the client API for MeetingService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Failed calls carry the code matching the status of the REST API: NOT_FOUND for 404, INVALID_ARGUMENT for 400,
+// ALREADY_EXISTS for an overlapping meeting and ABORTED for a meeting changed by another write since it was read.
+type MeetingServiceClient interface {
+	// CreateMeeting validates and books a meeting like POST /api/meetings and returns it with its id.
+	//
+	// Errors:
+	//   - INVALID_ARGUMENT: the meeting is invalid (REST and GraphQL: 400).
+	//   - ALREADY_EXISTS: a participant going for the meeting has another meeting at that time, the message names
+	//     the email. REST and the GraphQL createMeeting answer this with 400, their updates of an existing meeting
+	//     with 409.
+	CreateMeeting(ctx context.Context, in *CreateMeetingRequest, opts ...grpc.CallOption) (*Meeting, error)
+	// GetMeeting returns one meeting like GET /api/meeting?id=.
+	GetMeeting(ctx context.Context, in *GetMeetingRequest, opts ...grpc.CallOption) (*Meeting, error)
+	// ListMeetingsByRange returns the meetings and occurrences between start and end like GET /api/meetings?start=&end=.
+	ListMeetingsByRange(ctx context.Context, in *ListMeetingsByRangeRequest, opts ...grpc.CallOption) (*ListMeetingsResponse, error)
+	// ListMeetingsByParticipant returns the meetings of a participant like GET /api/meetings?email=.
+	ListMeetingsByParticipant(ctx context.Context, in *ListMeetingsByParticipantRequest, opts ...grpc.CallOption) (*ListMeetingsResponse, error)
+	// WatchMeetings streams the changes of meetings like GET /api/meetings/stream.
+	WatchMeetings(ctx context.Context, in *WatchMeetingsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[MeetingEvent], error)
+}
+
+type meetingServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMeetingServiceClient(cc grpc.ClientConnInterface) MeetingServiceClient {
+	return &meetingServiceClient{cc}
+}
+
+func (c *meetingServiceClient) CreateMeeting(ctx context.Context, in *CreateMeetingRequest, opts ...grpc.CallOption) (*Meeting, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Meeting)
+	err := c.cc.Invoke(ctx, MeetingService_CreateMeeting_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *meetingServiceClient) GetMeeting(ctx context.Context, in *GetMeetingRequest, opts ...grpc.CallOption) (*Meeting, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Meeting)
+	err := c.cc.Invoke(ctx, MeetingService_GetMeeting_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *meetingServiceClient) ListMeetingsByRange(ctx context.Context, in *ListMeetingsByRangeRequest, opts ...grpc.CallOption) (*ListMeetingsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListMeetingsResponse)
+	err := c.cc.Invoke(ctx, MeetingService_ListMeetingsByRange_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *meetingServiceClient) ListMeetingsByParticipant(ctx context.Context, in *ListMeetingsByParticipantRequest, opts ...grpc.CallOption) (*ListMeetingsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListMeetingsResponse)
+	err := c.cc.Invoke(ctx, MeetingService_ListMeetingsByParticipant_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *meetingServiceClient) WatchMeetings(ctx context.Context, in *WatchMeetingsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[MeetingEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &MeetingService_ServiceDesc.Streams[0], MeetingService_WatchMeetings_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchMeetingsRequest, MeetingEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MeetingService_WatchMeetingsClient = grpc.ServerStreamingClient[MeetingEvent]
+
+// MeetingServiceServer is the server API for MeetingService service.
+// All implementations must embed UnimplementedMeetingServiceServer
+// for forward compatibility.
+//
+// Failed calls carry the code matching the status of the REST API: NOT_FOUND for 404, INVALID_ARGUMENT for 400,
+// ALREADY_EXISTS for an overlapping meeting and ABORTED for a meeting changed by another write since it was read.
+type MeetingServiceServer interface {
+	// CreateMeeting validates and books a meeting like POST /api/meetings and returns it with its id.
+	//
+	// Errors:
+	//   - INVALID_ARGUMENT: the meeting is invalid (REST and GraphQL: 400).
+	//   - ALREADY_EXISTS: a participant going for the meeting has another meeting at that time, the message names
+	//     the email. REST and the GraphQL createMeeting answer this with 400, their updates of an existing meeting
+	//     with 409.
+	CreateMeeting(context.Context, *CreateMeetingRequest) (*Meeting, error)
+	// GetMeeting returns one meeting like GET /api/meeting?id=.
+	GetMeeting(context.Context, *GetMeetingRequest) (*Meeting, error)
+	// ListMeetingsByRange returns the meetings and occurrences between start and end like GET /api/meetings?start=&end=.
+	ListMeetingsByRange(context.Context, *ListMeetingsByRangeRequest) (*ListMeetingsResponse, error)
+	// ListMeetingsByParticipant returns the meetings of a participant like GET /api/meetings?email=.
+	ListMeetingsByParticipant(context.Context, *ListMeetingsByParticipantRequest) (*ListMeetingsResponse, error)
+	// WatchMeetings streams the changes of meetings like GET /api/meetings/stream.
+	WatchMeetings(*WatchMeetingsRequest, grpc.ServerStreamingServer[MeetingEvent]) error
+	mustEmbedUnimplementedMeetingServiceServer()
+}
+
+// UnimplementedMeetingServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedMeetingServiceServer struct{}
+
+func (UnimplementedMeetingServiceServer) CreateMeeting(context.Context, *CreateMeetingRequest) (*Meeting, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateMeeting not implemented")
+}
+func (UnimplementedMeetingServiceServer) GetMeeting(context.Context, *GetMeetingRequest) (*Meeting, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMeeting not implemented")
+}
+func (UnimplementedMeetingServiceServer) ListMeetingsByRange(context.Context, *ListMeetingsByRangeRequest) (*ListMeetingsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListMeetingsByRange not implemented")
+}
+func (UnimplementedMeetingServiceServer) ListMeetingsByParticipant(context.Context, *ListMeetingsByParticipantRequest) (*ListMeetingsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListMeetingsByParticipant not implemented")
+}
+func (UnimplementedMeetingServiceServer) WatchMeetings(*WatchMeetingsRequest, grpc.ServerStreamingServer[MeetingEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchMeetings not implemented")
+}
+func (UnimplementedMeetingServiceServer) mustEmbedUnimplementedMeetingServiceServer() {}
+func (UnimplementedMeetingServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeMeetingServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MeetingServiceServer will
+// result in compilation errors.
+type UnsafeMeetingServiceServer interface {
+	mustEmbedUnimplementedMeetingServiceServer()
+}
+
+func RegisterMeetingServiceServer(s grpc.ServiceRegistrar, srv MeetingServiceServer) {
+	// If the following call pancis, it indicates UnimplementedMeetingServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&MeetingService_ServiceDesc, srv)
+}
+
+func _MeetingService_CreateMeeting_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateMeetingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MeetingServiceServer).CreateMeeting(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MeetingService_CreateMeeting_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MeetingServiceServer).CreateMeeting(ctx, req.(*CreateMeetingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MeetingService_GetMeeting_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMeetingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MeetingServiceServer).GetMeeting(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MeetingService_GetMeeting_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MeetingServiceServer).GetMeeting(ctx, req.(*GetMeetingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MeetingService_ListMeetingsByRange_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListMeetingsByRangeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MeetingServiceServer).ListMeetingsByRange(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MeetingService_ListMeetingsByRange_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MeetingServiceServer).ListMeetingsByRange(ctx, req.(*ListMeetingsByRangeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MeetingService_ListMeetingsByParticipant_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListMeetingsByParticipantRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MeetingServiceServer).ListMeetingsByParticipant(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MeetingService_ListMeetingsByParticipant_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MeetingServiceServer).ListMeetingsByParticipant(ctx, req.(*ListMeetingsByParticipantRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MeetingService_WatchMeetings_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchMeetingsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MeetingServiceServer).WatchMeetings(m, &grpc.GenericServerStream[WatchMeetingsRequest, MeetingEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MeetingService_WatchMeetingsServer = grpc.ServerStreamingServer[MeetingEvent]
+
+// MeetingService_ServiceDesc is the grpc.ServiceDesc for MeetingService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var MeetingService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "meetings.v1.MeetingService",
+	HandlerType: (*MeetingServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateMeeting",
+			Handler:    _MeetingService_CreateMeeting_Handler,
+		},
+		{
+			MethodName: "GetMeeting",
+			Handler:    _MeetingService_GetMeeting_Handler,
+		},
+		{
+			MethodName: "ListMeetingsByRange",
+			Handler:    _MeetingService_ListMeetingsByRange_Handler,
+		},
+		{
+			MethodName: "ListMeetingsByParticipant",
+			Handler:    _MeetingService_ListMeetingsByParticipant_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchMeetings",
+			Handler:       _MeetingService_WatchMeetings_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "meetingpb/meetings.proto",
+}
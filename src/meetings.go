@@ -1,66 +1,129 @@
 package main
 
 import (
+	"github.com/dxmxnlord/golang-api/src/model"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/bson"
 	"time"
 	"errors"
 	"fmt"
 	"regexp"
+	"sort"
+	"strings"
+	"unicode"
 )
 
 // Object Types
 
+// StatusCancelled is the Meeting.Status of a cancelled meeting, active meetings have an empty status
+const StatusCancelled = "cancelled"
+
+/*
+	type Meeting - model.Meeting with the fields only the server keeps
+	@attributes:
+		Meeting - the fields of the api, shared with the Go client, stored inline in the document
+		NextReminder - next due reminder, nil for none
+		Version - incremented by every write, see MeetingStore.UpdateMeeting
+*/
+
 type Meeting struct {
-	ID primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
-	Title string `json:"title" bson:"title"`
-	StartTime time.Time `json:"start_time" bson:"start_time"`
-	EndTime time.Time `json:"end_time" bson:"end_time"`
-	CreatedAt time.Time `json:"created_at,omitempty" bson:"created_at,omitempty"`
-	Participants []Participant `json:"participants,omitempty" bson:"participants,omitempty"`
+	model.Meeting `bson:",inline"`
+	NextReminder *time.Time `json:"-" bson:"next_reminder,omitempty"`
+	Version int64 `json:"-" bson:"version"`
 }
 
-type Participant struct {
-	Email string `json:"email" bson:"email"`
-	Name string `json:"name" bson:"name"`
-	RSVP string `json:"rsvp" bson:"rsvp"`
+// Participant is a participant of a meeting, see model.Participant
+type Participant = model.Participant
+
+/*
+	function Meeting.participant()
+	@params:
+		email - participant email
+	@return:
+		Participant - participant with email
+		bool - true if the meeting has a participant with email
+*/
+
+func (meeting *Meeting) participant(email string) (Participant, bool) {
+	for _, participant := range meeting.Participants {
+		if participant.Email == email {
+			return participant, true
+		}
+	}
+	return Participant{}, false
 }
 
 /*
-	function Meeting.createMeeting()
+	function Meeting.attendees()
+	@return:
+		[]string - sorted emails of the participants going for the meeting (rsvp Yes), none if it is cancelled
+	@description:
+		stores lock participants in this order so that concurrent bookings always reserve them in the same order
+*/
+
+func (meeting *Meeting) attendees() []string {
+	var emails []string
+	if meeting.Status == StatusCancelled {
+		return emails
+	}
+	for _, participant := range meeting.Participants {
+		if participant.RSVP == "Yes" {
+			emails = append(emails, participant.Email)
+		}
+	}
+	sort.Strings(emails)
+	return emails
+}
+
+/*
+	function Meeting.validate()
 	@purpose:
-		inserts meeting object into collection
-	@params:
-		db - mongoDb database instance
+		check the meeting fields before writing it to the store
 	@description:
-		get collection and context
-		set the CreatedAt timestamp to current time
 		perform checks:
 			ensure starttime is before the end time
+			ensure status is empty or cancelled
+			ensure the titles of the meeting and its overrides have no control characters but line breaks and tabs
+			ensure the recurrence rule, exceptions and overrides are valid
+			ensure the reminders are valid
 			for each participant in the meeting:
 				ensure email is used only once
 				ensure email is in right format
-				ensure meetings are not be overlapped for participant (if rsvp is yes)
+				ensure name has no control characters
 				ensure rsvp is in chosen values
-		call collection.InsertOne() to insert meeting record
+		overlapping meetings are checked by the store while writing
 	@return:
-		mongo.InsertOneResult - InsertOneResult | nil
 		error - nil | error
 */
 
-func (meeting *Meeting) createMeeting (db *mongo.Database) (*mongo.InsertOneResult, error) {
-
-	// get collection and context
-	collection := db.Collection("meetings")
-	ctx := getContext(10)
-
-	// set the CreatedAt timestamp to current time
-	meeting.CreatedAt = time.Now()
+func (meeting *Meeting) validate () error {
 
 	// ensure starttime is before the end time
 	if meeting.EndTime.Before(meeting.StartTime) == true {
-		return nil, errors.New("Start time is not before End time")
+		return errors.New("Start time is not before End time")
+	}
+
+	// ensure status chosen values
+	if meeting.Status != "" && meeting.Status != StatusCancelled {
+		return errors.New("Invalid status")
+	}
+
+	// ensure no control characters, they would break the lines of calendars and mails
+	// line breaks of titles are escaped in calendars and mails, names are written as quoted parameters which can have none
+	if hasControl(meeting.Title, "\r\n\t") {
+		return errors.New("Invalid character in title")
+	}
+	for _, override := range meeting.Overrides {
+		if hasControl(override.Title, "\r\n\t") {
+			return errors.New("Invalid character in override title")
+		}
+	}
+
+	if err := meeting.validateRecurrence(); err != nil {
+		return err
+	}
+
+	if err := meeting.validateReminders(); err != nil {
+		return err
 	}
 
 	var emails map[string]bool = map[string]bool{}
@@ -72,7 +135,7 @@ func (meeting *Meeting) createMeeting (db *mongo.Database) (*mongo.InsertOneResu
 		// ensure only one email of each participant
 		_,ok := emails[participant.Email]
 		if ok == true {
-			return nil, errors.New("Repeated email found")
+			return errors.New("Repeated email found")
 		} else {
 			emails[participant.Email] = true;
 		}
@@ -80,78 +143,222 @@ func (meeting *Meeting) createMeeting (db *mongo.Database) (*mongo.InsertOneResu
 		// check email format
 		match, _ := regexp.MatchString("^[a-zA-Z0-9.!#$%&'*+\\/=?^_`{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$", participant.Email)
 		if match == false {
-			return nil, errors.New("Invalid email in participant list")
+			return errors.New("Invalid email in participant list")
 		}
 
-		// if rsvp is Yes, check for overlapping meetings
-		if participant.RSVP == "Yes" {
-			filter := bson.M{
-				// participant is going for meeting
-				"participants": bson.D{
-					{"email", participant.Email},
-					{"name", participant.Name},
-					{"rsvp", "Yes"},
-				},
-			 	"$or" : bson.A{
-			 		// meeting starting and ending between another meeting
-			 		bson.M{
-			 			"start_time" : bson.M{
-			 				"$lte" : meeting.StartTime,
-			 			},
-			 			"end_time" : bson.M{
-			 				"$gte": meeting.EndTime,
-			 			},
-			 		},
-			 		// meeting starting before another but not getting over before start
-			 		bson.M{
-			 			"start_time" : bson.M{
-			 				"$gte" : meeting.StartTime,
-			 				"$lt": meeting.EndTime,
-			 			},
-			 			"end_time" : bson.M{
-			 				"$gte": meeting.StartTime,
-			 			},
-			 		},
-			 		// meeting starting during another meeting
-			 		bson.M{
-			 			"start_time" : bson.M{
-			 				"$lte" : meeting.StartTime,
-			 			},
-			 			"end_time" : bson.M{
-			 				"$gt": meeting.StartTime,
-			 				"$lte": meeting.EndTime,
-			 			},
-			 		},
-				}, 
-			}
-
-			// find meetings with overlapping conditions
-			cursor,err := collection.Find(ctx,filter)
-			var meetingsCheck []bson.M
-			if err = cursor.All(ctx, &meetingsCheck); err != nil {
-			    return nil,err
-			}
-			// if returned meetings are not none then return error
-			if len(meetingsCheck) > 0 {
-				return nil, errors.New("Overlapping meeting for email " + participant.Email)
-			}
+		if hasControl(participant.Name, "") {
+			return errors.New("Invalid character in participant name")
 		}
 
 		// ensure rsvp chosen values
 		if participant.RSVP == "Yes" || participant.RSVP == "No" || participant.RSVP == "Maybe" || participant.RSVP == "Not Answered" {
 			continue;
 		} else {
-			return nil, errors.New("Invalid RSVP")
+			return errors.New("Invalid RSVP")
 		}
 	}
+	return nil
+}
+
+/*
+	function hasControl()
+	@params:
+		text - text to check
+		allowed - control characters which are fine in text
+	@return:
+		bool - true if text has another control character like NUL or a line break
+*/
+
+func hasControl(text, allowed string) bool {
+	return strings.IndexFunc(text, func(r rune) bool {
+		return unicode.IsControl(r) && !strings.ContainsRune(allowed, r)
+	}) >= 0
+}
+
+/*
+	function Meeting.createMeeting()
+	@purpose:
+		inserts meeting object into the store
+	@params:
+		store - MeetingStore instance
+	@description:
+		set the CreatedAt timestamp to current time
+		call Meeting.validate() to check the meeting
+		call Meeting.prepareReminders() to schedule the first reminder
+		call store.InsertMeeting() which ensures meetings are not overlapped for participants (if rsvp is yes)
+		and inserts the meeting record in one atomic step. For recurring meetings every occurrence is checked
+		the store records a meeting.created event in its outbox together with the meeting
+	@return:
+		primitive.ObjectID - inserted id | primitive.NilObjectID
+		error - nil | *OverlapError | error
+*/
+
+func (meeting *Meeting) createMeeting (store MeetingStore) (primitive.ObjectID, error) {
+
+	// set the CreatedAt timestamp to current time
+	meeting.CreatedAt = time.Now()
+
+	if err := meeting.validate(); err != nil {
+		return primitive.NilObjectID, err
+	}
+	meeting.prepareRecurrence()
+	meeting.prepareReminders(meeting.CreatedAt)
 
-	// insert the record into collection
-	result, err := collection.InsertOne(ctx, meeting)
+	// check overlaps and insert the record into the store
+	id, err := store.InsertMeeting(meeting, Event{Type: EventMeetingCreated})
 	if err != nil {
+		if overlap, ok := err.(*OverlapError); ok {
+			return primitive.NilObjectID, overlap
+		}
 		fmt.Println(err)
-		return nil, errors.New("Could not create")
+		return primitive.NilObjectID, errors.New("Could not create")
+	}
+	return id, nil
+}
+
+/*
+	function Meeting.updateMeeting()
+	@purpose:
+		replaces the stored meeting with the same ID
+	@params:
+		store - MeetingStore instance
+		stored - the meeting as it was loaded from the store
+	@description:
+		refuse to change the status, meetings are cancelled with Meeting.cancelMeeting() so that a meeting.cancelled event is recorded
+		call Meeting.writeUpdate() with a meeting.updated event
+	@return:
+		error - nil | ErrStatusChange | ErrMeetingNotFound | ErrMeetingChanged | *OverlapError | error
+*/
+
+func (meeting *Meeting) updateMeeting (store MeetingStore, stored Meeting) error {
+	if meeting.Status != stored.Status {
+		return ErrStatusChange
 	}
-	return result, nil
+	return meeting.writeUpdate(store, Event{Type: EventMeetingUpdated})
+}
+
+/*
+	function Meeting.writeUpdate()
+	@params:
+		store - MeetingStore instance
+		event - event the store records in its outbox together with the meeting
+	@description:
+		call Meeting.validate() to check the meeting
+		call Meeting.prepareReminders() to reschedule the reminders
+		call store.UpdateMeeting() which ensures meetings are not overlapped for participants (if rsvp is yes),
+		leaving out the meeting itself, and replaces the record in one atomic step if it still has Meeting.Version
+	@return:
+		error - nil | ErrMeetingNotFound | ErrMeetingChanged | *OverlapError | error
+*/
+
+func (meeting *Meeting) writeUpdate (store MeetingStore, event Event) error {
+
+	if err := meeting.validate(); err != nil {
+		return err
+	}
+	meeting.prepareRecurrence()
+	meeting.prepareReminders(time.Now())
+
+	// check overlaps and replace the record in the store
+	err := store.UpdateMeeting(meeting, event)
+	if _, ok := err.(*OverlapError); ok || err == nil || err == ErrMeetingNotFound || err == ErrMeetingChanged {
+		return err
+	}
+	fmt.Println(err)
+	return errors.New("Could not update")
+}
+
+/*
+	function Meeting.cancelMeeting()
+	@purpose:
+		cancel the stored meeting with Meeting.ID
+	@params:
+		store - MeetingStore instance
+	@description:
+		with Meeting.change() load the meeting, set its status to cancelled and write it back with Meeting.writeUpdate()
+		and a meeting.cancelled event, so writes that read the meeting before stay cancelled or fail with ErrMeetingChanged
+		a cancelled meeting stays readable but no longer blocks its participants
+	@return:
+		error - nil | ErrMeetingNotFound | ErrMeetingChanged | error
+*/
+
+func (meeting *Meeting) cancelMeeting (store MeetingStore) error {
+	return meeting.change(store, func() error {
+		meeting.Status = StatusCancelled
+		return meeting.writeUpdate(store, Event{Type: EventMeetingCancelled})
+	})
+}
+
+/*
+	function Meeting.setRSVP()
+	@purpose:
+		change the rsvp of one participant of the stored meeting with Meeting.ID
+	@params:
+		store - MeetingStore instance
+		email - participant email
+		rsvp - new rsvp value
+	@description:
+		with Meeting.change() load the meeting, set the rsvp of the participant and write it back with Meeting.writeUpdate()
+		and an rsvp.changed event
+		switching to Yes runs the same overlap check as Meeting.createMeeting()
+	@return:
+		error - nil | ErrMeetingNotFound | ErrParticipantNotFound | ErrMeetingChanged | *OverlapError | error
+*/
+
+func (meeting *Meeting) setRSVP (store MeetingStore, email, rsvp string) error {
+	return meeting.change(store, func() error {
+		for i := range meeting.Participants {
+			if meeting.Participants[i].Email == email {
+				meeting.Participants[i].RSVP = rsvp
+				return meeting.writeUpdate(store, Event{Type: EventRSVPChanged, Email: email})
+			}
+		}
+		return ErrParticipantNotFound
+	})
+}
+
+// maxChangeAttempts is how often Meeting.change() loads and writes a meeting that keeps being changed by other requests
+const maxChangeAttempts = 3
+
+/*
+	function Meeting.change()
+	@params:
+		store - MeetingStore instance
+		apply - changes the loaded meeting and writes it with Meeting.writeUpdate()
+	@description:
+		load the meeting with Meeting.ID and call apply. If another request wrote the meeting between the read and the write
+		load it again and call apply on the new version, at most maxChangeAttempts times
+		used by the changes which do not depend on a copy held by the client, so they are applied on top of concurrent writes
+	@return:
+		error - nil | ErrMeetingNotFound | ErrMeetingChanged after the last attempt | error of apply
+*/
+
+func (meeting *Meeting) change (store MeetingStore, apply func() error) error {
+	err := ErrMeetingChanged
+	for attempt := 0; attempt < maxChangeAttempts && err == ErrMeetingChanged; attempt++ {
+		if err = meeting.getMeeting(store); err != nil {
+			return err
+		}
+		err = apply()
+	}
+	return err
+}
+
+/*
+	function deleteMeeting()
+	@purpose:
+		remove a meeting from the store
+	@params:
+		store - MeetingStore instance
+		id - meeting id
+	@description:
+		the store records a meeting.deleted event in its outbox together with the removal
+	@return:
+		error - nil | ErrMeetingNotFound | error
+*/
+
+func deleteMeeting (store MeetingStore, id primitive.ObjectID) error {
+	return store.DeleteMeeting(id, Event{Type: EventMeetingDeleted})
 }
 
 /*
@@ -159,25 +366,50 @@ func (meeting *Meeting) createMeeting (db *mongo.Database) (*mongo.InsertOneResu
 	@purpose:
 		get individual meeting
 	@params:
-		db - mongoDb database instance
+		store - MeetingStore instance
 	@description:
-		get collection and context
-		design filter with bson and set Meeting.ID as mongo ObjectID
-		query the collection and populate Meeting instance
+		query the store with Meeting.ID and populate Meeting instance
 	@return:
-		error - error | nil
+		error - ErrMeetingNotFound | error | nil
 */
 
-func (meeting *Meeting) getMeeting (db *mongo.Database) error{
-	collection := db.Collection("meetings")
-	ctx := getContext(10)
+func (meeting *Meeting) getMeeting (store MeetingStore) error{
+	found, err := store.GetMeeting(meeting.ID)
+	if err != nil {
+		return err
+	}
+	*meeting = found
+	return nil
+}
 
-	// design filter
-	filter := bson.M{"_id": meeting.ID}
+/*
+	type Paging - how a list request wants its results paged
+	@attributes:
+		Page - page number starting at 1, -1 for no paging
+		Size - size of each page
+		Keyset - page with cursors instead of page numbers
+		After - cursor to continue after in keyset paging, nil for the first page
+*/
 
-	// query collections
-	err := collection.FindOne(ctx, filter).Decode(&meeting)
-	return err
+type Paging struct {
+	Page int
+	Size int
+	Keyset bool
+	After *MeetingCursor
+}
+
+/*
+	type MeetingPage - one page of a list request
+	@attributes:
+		Meetings - meetings of the page
+		Total - total number of matching meetings
+		Next - cursor for the following page in keyset paging, nil on the last page
+*/
+
+type MeetingPage struct {
+	Meetings []Meeting
+	Total int64
+	Next *MeetingCursor
 }
 
 /*
@@ -185,102 +417,172 @@ func (meeting *Meeting) getMeeting (db *mongo.Database) error{
 	@purpose:
 		get meetings in a time range and also paginate
 	@params:
-		db - mongoDb database instance
+		store - MeetingStore instance
 		st_time - starting time
 		en_time - starting time
-		page - page number
-		pageSize - size of each page
+		paging - page wanted by the request
+		includeCancelled - also return cancelled meetings
 	@description:
-		get collection and context
-		design filter with bson and query for times between starttime and endtime
-		paginate result
+		call searchMeetings() with the time range
 	@return:
-		[]bson.M - []Meeting | nil
+		MeetingPage - page of meetings
 		error - error | nil
 */
 
-func getMeetings (db *mongo.Database, st_time,en_time time.Time, page,pageSize int) ([]Meeting,error){
-	collection := db.Collection("meetings")
-	ctx := getContext(10)
+func getMeetings (store MeetingStore, st_time,en_time time.Time, paging Paging, includeCancelled bool) (MeetingPage,error){
+	query := MeetingQuery{Start: st_time, End: en_time, IncludeCancelled: includeCancelled}
+	return searchMeetings(store,query,paging)
+}
 
-	// design filter
-	filter := bson.M{"start_time" : bson.M{"$gte" : st_time}, "end_time" : bson.M{"$lte" : en_time}}
+/*
+	function getMeetingsParticipant()
+	@purpose:
+		get meetings with a participant email and also paginate
+	@params:
+		store - MeetingStore instance
+		email - participant email
+		paging - page wanted by the request
+		includeCancelled - also return cancelled meetings
+	@description:
+		call searchMeetings() with the email, the store skips to the page and limits the result
+	@return:
+		MeetingPage - page of meetings
+		error - error | nil
+*/
 
-	var meetings []Meeting
-	// find meetings with filter
-	cursor,err := collection.Find(ctx,filter)
-	if err != nil {
-		fmt.Println(err)
-	    return nil,err
+func getMeetingsParticipant (store MeetingStore, email string, paging Paging, includeCancelled bool) (MeetingPage,error){
+	query := MeetingQuery{Email: email, IncludeCancelled: includeCancelled}
+	return searchMeetings(store,query,paging)
+}
+
+/*
+	function searchMeetings()
+	@purpose:
+		get meetings matching any combination of the filters and also paginate
+	@params:
+		store - MeetingStore instance
+		query - filters of the request, Start and End are used together, Email on its own or with them
+		paging - page wanted by the request
+	@description:
+		without a time range query the store with the filters, recurring meetings are returned as series
+		with a time range query the store for recurring meetings running between starttime and endtime
+		and expand them into the occurrences starting and ending between starttime and endtime,
+		then query the store for single meetings between starttime and endtime and page them together with the occurrences,
+		sorted by start time and id across all series
+	@return:
+		MeetingPage - page of meetings
+		error - error | nil
+*/
+
+func searchMeetings (store MeetingStore, query MeetingQuery, paging Paging) (MeetingPage,error){
+	if query.Start.IsZero() || query.End.IsZero() {
+		return findPage(store,query,paging,nil)
 	}
-	// call cursor to populate all objects found into meetings object
-	if err = cursor.All(ctx, &meetings); err != nil {
-		fmt.Println(err)
-	    return nil,err
+
+	seriesQuery := query
+	seriesQuery.Recurring = true
+	series, _, err := store.FindMeetings(seriesQuery)
+	if err != nil {
+		return MeetingPage{},err
 	}
 
-	// if paging is to be done
-	if page == -1 {
-		return meetings,nil
-	} else {
-		// return slice of meeting data for page
-		if len(meetings) >=  pageSize * page {
-			return meetings[(page-1) * pageSize : page * pageSize],nil
-		} else if len(meetings) <  pageSize * page && len(meetings) > (page-1)* pageSize { 
-			return meetings[(page-1) * pageSize : len(meetings)],nil
-		} else {
-			return nil,nil
+	var occurrences []Meeting
+	for _, meeting := range series {
+		for _, occurrence := range meeting.occurrences(query.Start, query.End) {
+			if !occurrence.StartTime.Before(query.Start) && !occurrence.EndTime.After(query.End) {
+				occurrences = append(occurrences, occurrence)
+			}
 		}
 	}
+	// the occurrences of several series interleave
+	sortMeetings(occurrences)
+	return findPage(store,query,paging,occurrences)
 }
 
 /*
-	function getMeetingsParticipant()
-	@purpose:
-		get meetings with a participant email and also paginate
+	function findPage()
 	@params:
-		db - mongoDb database instance
-		email - participant email
-		page - page number
-		pageSize - size of each page
+		store - MeetingStore instance
+		query - filters of the request
+		paging - page wanted by the request
+		extra - meetings not in the store (expanded occurrences) sorted like sortMeetings(), to page together with the query results
 	@description:
-		get collection and context
-		design filter with bson and query for email
-		paginate result
+		for page numbers set Skip and Limit of the query for the page
+		for keyset paging continue after the cursor and ask for one meeting more than the page size,
+		if it is there the page is not the last one and the next cursor points after the last meeting of the page
+		with extra meetings the page can hold meetings from both sources, so the query fetches every meeting up to
+		the end of the page, then the extra meetings after the cursor and the query results are merged
+		and the page is cut out of the merged list
 	@return:
-		[]bson.M - []Meeting | nil
+		MeetingPage - page of meetings
 		error - error | nil
 */
 
-func getMeetingsParticipant (db *mongo.Database, email string, page,pageSize int) ([]Meeting,error){
-	collection := db.Collection("meetings")
-	ctx := getContext(10)
-
-	// design filter
-	filter := bson.M{"participants.email": email}
+func findPage (store MeetingStore, query MeetingQuery, paging Paging, extra []Meeting) (MeetingPage,error) {
+	skip := 0
+	if paging.Keyset {
+		query.After = paging.After
+		query.Limit = int64(paging.Size + 1)
+	} else if paging.Page != -1 {
+		skip = (paging.Page-1) * paging.Size
+		query.Skip = int64(skip)
+		query.Limit = int64(paging.Size)
+		if len(extra) > 0 {
+			query.Skip = 0
+			query.Limit = int64(skip + paging.Size)
+		}
+	}
 
-	// query collections
-	var meetings []Meeting
-	cursor,err := collection.Find(ctx,filter)
+	meetings, total, err := store.FindMeetings(query)
 	if err != nil {
-		fmt.Println(err)
-	    return nil,err
-	}
-	if err = cursor.All(ctx, &meetings); err != nil {
-	    return nil,err
+		return MeetingPage{},err
 	}
 
-	// pagination
-	if page == -1 {
-		return meetings,nil
-	} else {
-		// return slice of meeting data for page
-		if len(meetings) >= pageSize * page {
-			return meetings[(page-1)*pageSize : page*pageSize],nil
-		} else if len(meetings) < pageSize * page && len(meetings) > (page-1)*pageSize { 
-			return meetings[(page-1)*pageSize : len(meetings)],nil
-		} else {
-			return nil,nil
+	if len(extra) > 0 {
+		total += int64(len(extra))
+		if paging.After != nil {
+			var after []Meeting
+			for _, meeting := range extra {
+				if paging.After.isBefore(meeting) {
+					after = append(after, meeting)
+				}
+			}
+			extra = after
+		}
+		meetings = mergeMeetings(meetings, extra)
+		if !paging.Keyset && paging.Page != -1 {
+			if skip > len(meetings) {
+				skip = len(meetings)
+			}
+			end := skip + paging.Size
+			if end > len(meetings) {
+				end = len(meetings)
+			}
+			meetings = meetings[skip:end]
 		}
 	}
-}
\ No newline at end of file
+
+	page := MeetingPage{Meetings: meetings, Total: total}
+	if paging.Keyset && len(meetings) > paging.Size {
+		page.Meetings = meetings[:paging.Size]
+		page.Next = cursorAfter(page.Meetings[paging.Size-1])
+	}
+	return page,nil
+}
+
+/*
+	function sortMeetings()
+	@params:
+		meetings - meetings to sort in place
+	@description:
+		order meetings by start time, then by id like the mongoDb queries
+*/
+
+func sortMeetings(meetings []Meeting) {
+	sort.SliceStable(meetings, func(i, j int) bool {
+		if !meetings[i].StartTime.Equal(meetings[j].StartTime) {
+			return meetings[i].StartTime.Before(meetings[j].StartTime)
+		}
+		return meetings[i].ID.Hex() < meetings[j].ID.Hex()
+	})
+}
@@ -0,0 +1,71 @@
+// Package model has the meeting types shared by the server and the Go client, with the json fields of the api
+// and the bson fields of the mongoDb meetings collection.
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+/*
+	type Meeting - a meeting as the api reads and writes it
+	@attributes:
+		ID - id of the meeting
+		Title - title of the meeting
+		StartTime, EndTime - times of the meeting, of the first occurrence for a series
+		CreatedAt - time the meeting was created, set by the server
+		Participants - participants of the meeting
+		Status - empty for active meetings, cancelled for cancelled ones
+		Recurrence - RRULE of a series, empty for single meetings
+		Exceptions - starts of the occurrences left out of the series
+		Overrides - occurrences of the series with other times or titles
+		SeriesEnd - end of the last occurrence of a series with COUNT or UNTIL, set by the server
+		RecurrenceID - start of the occurrence as generated by the rule, only set on occurrences of a series
+		Reminders - minutes before each occurrence the participants going for it are reminded
+*/
+
+type Meeting struct {
+	ID           primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	Title        string             `json:"title" bson:"title"`
+	StartTime    time.Time          `json:"start_time" bson:"start_time"`
+	EndTime      time.Time          `json:"end_time" bson:"end_time"`
+	CreatedAt    time.Time          `json:"created_at,omitempty" bson:"created_at,omitempty"`
+	Participants []Participant      `json:"participants,omitempty" bson:"participants,omitempty"`
+	Status       string             `json:"status,omitempty" bson:"status,omitempty"`
+	Recurrence   string             `json:"recurrence,omitempty" bson:"recurrence,omitempty"`
+	Exceptions   []time.Time        `json:"exceptions,omitempty" bson:"exceptions,omitempty"`
+	Overrides    []Override         `json:"overrides,omitempty" bson:"overrides,omitempty"`
+	SeriesEnd    *time.Time         `json:"series_end,omitempty" bson:"series_end,omitempty"`
+	RecurrenceID *time.Time         `json:"recurrence_id,omitempty" bson:"-"`
+	Reminders    []int              `json:"reminders,omitempty" bson:"reminders,omitempty"`
+}
+
+/*
+	type Participant - a participant of a meeting
+	@attributes:
+		Email - email of the participant, unique within a meeting
+		Name - name of the participant
+		RSVP - Yes, No, Maybe or Not Answered
+*/
+
+type Participant struct {
+	Email string `json:"email" bson:"email"`
+	Name  string `json:"name" bson:"name"`
+	RSVP  string `json:"rsvp" bson:"rsvp"`
+}
+
+/*
+	type Override - changes one occurrence of a recurring meeting
+	@attributes:
+		RecurrenceID - start of the occurrence as generated by the rule
+		Title - title of the occurrence, the title of the series if empty
+		StartTime, EndTime - new times of the occurrence
+*/
+
+type Override struct {
+	RecurrenceID time.Time `json:"recurrence_id" bson:"recurrence_id"`
+	Title        string    `json:"title,omitempty" bson:"title,omitempty"`
+	StartTime    time.Time `json:"start_time" bson:"start_time"`
+	EndTime      time.Time `json:"end_time" bson:"end_time"`
+}
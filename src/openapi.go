@@ -0,0 +1,533 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// openAPIVersion is the version of the api in the OpenAPI document, raised with changes of the documented routes
+const openAPIVersion = "1.0.0"
+
+// openAPISchemas are the types described under components/schemas, their schemas are generated from the json tags
+var openAPISchemas = map[string]reflect.Type{
+	"Meeting":         reflect.TypeOf(Meeting{}),
+	"Participant":     reflect.TypeOf(Participant{}),
+	"Override":        reflect.TypeOf(Override{}),
+	"ImportResult":    reflect.TypeOf(ImportResult{}),
+	"CSVImportResult": reflect.TypeOf(CSVImportResult{}),
+	"Interval":        reflect.TypeOf(Interval{}),
+	"Slot":            reflect.TypeOf(Slot{}),
+	"Event":           reflect.TypeOf(Event{}),
+	"Webhook":         reflect.TypeOf(Webhook{}),
+	"Delivery":        reflect.TypeOf(Delivery{}),
+}
+
+// rsvpValues are the rsvp values accepted by Meeting.validate()
+var rsvpValues = []string{"Yes", "No", "Maybe", "Not Answered"}
+
+// swaggerUIFiles are the files of Swagger UI 5.18.2 (github.com/swaggo/files/v2 v2.0.2) served under /api/docs/ with their sha384,
+// a different version of the files fails TestOpenAPI instead of being blocked by the browser
+var swaggerUIFiles = map[string]string{
+	"swagger-ui.css":       "sha384-rcbEi6xgdPk0iWkAQzT2F3FeBJXdG+ydrawGlfHAFIZG7wU6aKbQaRewysYpmrlW",
+	"swagger-ui-bundle.js": "sha384-NXtFPpN61oWCuN4D42K6Zd5Rt2+uxeIT36R7kpXBuY9tLnZorzrJ4ykpqwJfgjpZ",
+}
+
+// openAPIDocs is the page of /api/docs, it renders /api/openapi.json with the Swagger UI of swaggerUIFiles
+const openAPIDocs = `<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<title>Meetings API</title>
+	<link rel="stylesheet" href="/api/docs/swagger-ui.css" integrity="sha384-rcbEi6xgdPk0iWkAQzT2F3FeBJXdG+ydrawGlfHAFIZG7wU6aKbQaRewysYpmrlW">
+</head>
+<body>
+	<div id="docs"></div>
+	<script src="/api/docs/swagger-ui-bundle.js" integrity="sha384-NXtFPpN61oWCuN4D42K6Zd5Rt2+uxeIT36R7kpXBuY9tLnZorzrJ4ykpqwJfgjpZ"></script>
+	<script>SwaggerUIBundle({url: "/api/openapi.json", dom_id: "#docs"})</script>
+</body>
+</html>
+`
+
+/*
+	type openAPIObject - an object of the OpenAPI document, marshalled as is
+*/
+
+type openAPIObject map[string]interface{}
+
+/*
+	function openAPIDocument()
+	@description:
+		describe every route of `Api.routes()`: /api/meeting, the query modes and imports of /api/meetings, the routes
+		on a single meeting, free/busy and slots, the calendar feed, the meeting stream and live calendars, webhooks,
+		/graphql and the docs. The schemas of the bodies are generated from the go types with jsonSchema(),
+		so they follow the json tags, MeetingPatch is Meeting without required fields for PATCH bodies
+		errors are the {"error": ...} bodies of errorResponse()
+	@return:
+		openAPIObject - OpenAPI 3.0 document
+*/
+
+func openAPIDocument() openAPIObject {
+	schemas := openAPIObject{
+		"Error": openAPIObject{
+			"type":                 "object",
+			"properties":           openAPIObject{"error": openAPIObject{"type": "string"}},
+			"required":             []string{"error"},
+			"additionalProperties": false,
+		},
+	}
+	for name, t := range openAPISchemas {
+		schemas[name] = jsonSchema(t, true)
+	}
+	participant := schemas["Participant"].(openAPIObject)["properties"].(openAPIObject)
+	participant["rsvp"].(openAPIObject)["enum"] = rsvpValues
+	patch := jsonSchema(reflect.TypeOf(Meeting{}), true)
+	delete(patch, "required")
+	schemas["MeetingPatch"] = patch
+
+	meetingID := openAPIObject{"name": "id", "in": "path", "required": true, "schema": jsonSchema(reflect.TypeOf(primitive.ObjectID{}), false)}
+	paging := []openAPIObject{
+		queryParam("page", "integer", "page number from 1, pages with page_size meetings"),
+		queryParam("page_size", "integer", "size of a page, capped at the max page size. Without page, page_size and cursor all meetings are returned"),
+		queryParam("cursor", "string", "keyset paging, empty for the first page, then the X-Next-Cursor of the previous page. Not with page"),
+		queryParam("include_cancelled", "boolean", "also return cancelled meetings"),
+		enumParam("format", "csv", "all matching meetings as csv instead of a page"),
+	}
+	webhookID := openAPIObject{"name": "id", "in": "path", "required": true, "schema": jsonSchema(reflect.TypeOf(primitive.ObjectID{}), false)}
+	emailsAndRange := []openAPIObject{
+		{"name": "emails", "in": "query", "required": true, "description": "comma separated emails of the participants, at most 50",
+			"schema": openAPIObject{"type": "string"}},
+		timeParam("start", "start of the time range"),
+		timeParam("end", "end of the time range"),
+	}
+	stringBody := func(description string, mediaTypes ...string) openAPIObject {
+		content := openAPIObject{}
+		for _, mediaType := range mediaTypes {
+			content[mediaType] = openAPIObject{"schema": openAPIObject{"type": "string"}}
+		}
+		return response(description, content)
+	}
+	notImplemented := func(feature string) openAPIObject {
+		return errorBody(feature + " are not supported by the store or disabled")
+	}
+	meetingBody := jsonBody(ref("Meeting"))
+	idBody := func(name string) openAPIObject {
+		return openAPIObject{
+			"type":       "object",
+			"properties": openAPIObject{name: jsonSchema(reflect.TypeOf(primitive.ObjectID{}), false)},
+			"required":   []string{name},
+		}
+	}
+
+	paths := openAPIObject{
+		"/api/meeting": openAPIObject{
+			"get": operation("getMeeting", "Get specific meeting",
+				[]openAPIObject{
+					{"name": "id", "in": "query", "required": true, "schema": openAPIObject{"type": "string"}, "description": "meeting id"},
+					enumParam("format", "ics", "the meeting as iCalendar document"),
+				}, nil,
+				openAPIObject{
+					"200": response("The meeting", openAPIObject{
+						"application/json": openAPIObject{"schema": ref("Meeting")},
+						"text/calendar":    openAPIObject{"schema": openAPIObject{"type": "string"}},
+					}),
+					"400": errorBody("Missing id"),
+					"404": errorBody("Meeting not found"),
+				}),
+		},
+		"/api/meetings": openAPIObject{
+			"get": operation("listMeetings", "Get meetings of a participant or in a time range",
+				append([]openAPIObject{
+					queryParam("email", "string", "meetings of the participant with email, not with start and end"),
+					timeParam("start", "meetings starting and ending between start and end, recurring meetings are expanded into occurrences. Not with email"),
+					timeParam("end", "end of the time range, with start"),
+				}, paging...), nil,
+				openAPIObject{
+					"200": openAPIObject{
+						"description": "Page of meetings ordered by start time",
+						"headers": openAPIObject{
+							"X-Total-Count": header("integer", "number of matching meetings"),
+							"X-Page":        header("integer", "page number, for numbered pages"),
+							"X-Page-Size":   header("integer", "size of the pages, for paged requests"),
+							"X-Next-Cursor": header("string", "cursor of the next page, for keyset paging unless it is the last page"),
+							"Link":          header("string", "first, prev, next and last pages"),
+						},
+						"content": openAPIObject{
+							"application/json": openAPIObject{"schema": openAPIObject{"type": "array", "items": ref("Meeting")}},
+							"text/csv":         openAPIObject{"schema": openAPIObject{"type": "string"}},
+						},
+					},
+					"400": errorBody("Neither only email nor only start and end, or invalid values"),
+				}),
+			"post": operation("createMeeting", "Create new meeting, or import meetings from iCalendar or csv", nil,
+				openAPIObject{
+					"required": true,
+					"content": openAPIObject{
+						"application/json": openAPIObject{"schema": ref("Meeting")},
+						"text/calendar":    openAPIObject{"schema": openAPIObject{"type": "string"}},
+						"text/csv":         openAPIObject{"schema": openAPIObject{"type": "string"}},
+					},
+				},
+				openAPIObject{
+					"201": response("The meeting was created", jsonBody(idBody("InsertedID"))),
+					"200": response("Report of an iCalendar (ImportResult) or csv (CSVImportResult) import", openAPIObject{
+						"application/json": openAPIObject{"schema": openAPIObject{"type": "array", "items": openAPIObject{
+							"oneOf": []openAPIObject{ref("ImportResult"), ref("CSVImportResult")},
+						}}},
+					}),
+					"400": errorBody("Invalid meeting, or it overlaps another meeting of a participant"),
+				}),
+		},
+		"/api/meetings/{id}": openAPIObject{
+			"parameters": []openAPIObject{meetingID},
+			"put": operation("replaceMeeting", "Replace meeting, the status is only changed by cancelMeeting", nil,
+				openAPIObject{"required": true, "content": meetingBody},
+				meetingResponses("The replaced meeting")),
+			"patch": operation("updateMeeting", "Update given meeting fields, the status is only changed by cancelMeeting", nil,
+				openAPIObject{"required": true, "content": jsonBody(ref("MeetingPatch"))},
+				meetingResponses("The updated meeting")),
+			"delete": operation("deleteMeeting", "Delete meeting", nil, nil,
+				openAPIObject{
+					"200": response("The meeting was deleted", jsonBody(idBody("DeletedID"))),
+					"404": errorBody("Meeting not found"),
+				}),
+		},
+		"/api/meetings/{id}/cancel": openAPIObject{
+			"parameters": []openAPIObject{meetingID},
+			"post": operation("cancelMeeting", "Cancel meeting", nil, nil,
+				openAPIObject{
+					"200": response("The cancelled meeting", meetingBody),
+					"404": errorBody("Meeting not found"),
+					"409": errorBody("Other requests kept changing the meeting"),
+				}),
+		},
+		"/api/meetings/{id}/rsvp": openAPIObject{
+			"parameters": []openAPIObject{meetingID},
+			"put": operation("setRSVP", "Change a participant's RSVP", nil,
+				openAPIObject{"required": true, "content": jsonBody(openAPIObject{
+					"type": "object",
+					"properties": openAPIObject{
+						"email": openAPIObject{"type": "string"},
+						"rsvp":  openAPIObject{"type": "string", "enum": rsvpValues},
+					},
+					"required": []string{"email", "rsvp"},
+				})},
+				meetingResponses("The meeting with the new rsvp")),
+		},
+		"/api/meetings/stream": openAPIObject{
+			"get": operation("streamMeetings", "Server-sent events of the changes of meetings",
+				[]openAPIObject{
+					queryParam("email", "string", "changes of meetings of the participant, not with start and end"),
+					timeParam("start", "changes of meetings with an occurrence between start and end, not with email"),
+					timeParam("end", "end of the time range, with start"),
+				}, nil,
+				openAPIObject{
+					"200": stringBody("Stream of events, the data of each event is the Event json", "text/event-stream"),
+					"400": errorBody("Neither only email nor only start and end, or invalid values"),
+					"501": notImplemented("Streams"),
+				}),
+		},
+		"/api/live": openAPIObject{
+			"get": operation("liveCalendar", "Websocket pushing the changes of subscribed calendars", nil, nil,
+				openAPIObject{
+					"101": openAPIObject{"description": "The connection was upgraded to a websocket"},
+					"400": errorBody("The request is no websocket handshake"),
+					"403": errorBody("The Origin is not the public host"),
+					"501": notImplemented("Live calendars"),
+				}),
+		},
+		"/api/freebusy": openAPIObject{
+			"get": operation("getFreeBusy", "Busy times of participants",
+				append(emailsAndRange, queryParam("include_maybe", "boolean", "meetings with rsvp Maybe count as busy")), nil,
+				openAPIObject{
+					"200": response("Busy intervals by email", jsonBody(openAPIObject{
+						"type": "object",
+						"properties": openAPIObject{
+							"start": openAPIObject{"type": "string", "format": "date-time"},
+							"end":   openAPIObject{"type": "string", "format": "date-time"},
+							"busy":  openAPIObject{"type": "object", "additionalProperties": openAPIObject{"type": "array", "items": ref("Interval")}},
+						},
+						"required": []string{"start", "end", "busy"},
+					})),
+					"400": errorBody("Missing or invalid emails, start or end"),
+				}),
+		},
+		"/api/slots": openAPIObject{
+			"get": operation("findSlots", "Suggest times at which participants are all free",
+				append(emailsAndRange,
+					queryParam("duration", "string", "length of the meeting like 30m or 1h30m"),
+					queryParam("step", "string", "distance between suggested starts, at least 1m, 15m by default"),
+					queryParam("work_start", "string", "start of the working hours as HH:MM"),
+					queryParam("work_end", "string", "end of the working hours as HH:MM"),
+					queryParam("weekdays", "string", "allowed days like MO,TU,WE,TH,FR"),
+					queryParam("tz", "string", "IANA time zone of the working hours and weekdays, UTC by default"),
+					queryParam("limit", "integer", "number of slots, 10 by default"),
+				), nil,
+				openAPIObject{
+					"200": response("Slots ranked by Maybe conflicts and start", jsonBody(openAPIObject{"type": "array", "items": ref("Slot")})),
+					"400": errorBody("Missing or invalid values, or more than 20000 steps in the window"),
+				}),
+		},
+		"/api/calendar": openAPIObject{
+			"get": operation("getCalendar", "iCalendar feed of the meetings of a participant",
+				[]openAPIObject{{"name": "email", "in": "query", "required": true, "schema": openAPIObject{"type": "string"}, "description": "email of the participant"}}, nil,
+				openAPIObject{
+					"200": stringBody("VCALENDAR with the meetings, cancelled ones with STATUS:CANCELLED", "text/calendar"),
+					"400": errorBody("Missing email"),
+				}),
+		},
+		"/api/webhooks": openAPIObject{
+			"get": operation("listWebhooks", "List webhooks without their secrets", nil, nil,
+				openAPIObject{
+					"200": response("The webhooks", jsonBody(openAPIObject{"type": "array", "items": ref("Webhook")})),
+					"501": notImplemented("Webhooks"),
+				}),
+			"post": operation("createWebhook", "Subscribe a url to meeting events", nil,
+				openAPIObject{"required": true, "content": jsonBody(openAPIObject{
+					"type": "object",
+					"properties": openAPIObject{
+						"url":    openAPIObject{"type": "string"},
+						"events": openAPIObject{"type": "array", "items": openAPIObject{"type": "string"}},
+						"secret": openAPIObject{"type": "string"},
+					},
+					"required": []string{"url"},
+				})},
+				openAPIObject{
+					"201": response("The webhook, the only response with its secret", jsonBody(ref("Webhook"))),
+					"400": errorBody("Invalid or non-public url, or unknown events"),
+					"501": notImplemented("Webhooks"),
+				}),
+		},
+		"/api/webhooks/{id}": openAPIObject{
+			"parameters": []openAPIObject{webhookID},
+			"get": operation("getWebhook", "Get webhook without its secret", nil, nil,
+				openAPIObject{
+					"200": response("The webhook", jsonBody(ref("Webhook"))),
+					"404": errorBody("Webhook not found"),
+				}),
+			"delete": operation("deleteWebhook", "Delete webhook and its delivery log", nil, nil,
+				openAPIObject{
+					"200": response("The webhook was deleted", jsonBody(idBody("DeletedID"))),
+					"404": errorBody("Webhook not found"),
+				}),
+		},
+		"/api/webhooks/{id}/deliveries": openAPIObject{
+			"parameters": []openAPIObject{webhookID},
+			"get": operation("listDeliveries", "Latest 100 delivery attempts, newest first", nil, nil,
+				openAPIObject{
+					"200": response("The delivery attempts", jsonBody(openAPIObject{"type": "array", "items": ref("Delivery")})),
+					"404": errorBody("Webhook not found"),
+				}),
+		},
+		"/api/webhooks/{id}/test": openAPIObject{
+			"parameters": []openAPIObject{webhookID},
+			"post": operation("testWebhook", "Post a webhook.test event once", nil, nil,
+				openAPIObject{
+					"200": response("The logged attempt", jsonBody(ref("Delivery"))),
+					"404": errorBody("Webhook not found"),
+				}),
+		},
+		"/graphql": openAPIObject{
+			"post": operation("graphql", "GraphQL queries and mutations of meetings", nil,
+				openAPIObject{"required": true, "content": jsonBody(openAPIObject{
+					"type": "object",
+					"properties": openAPIObject{
+						"query":         openAPIObject{"type": "string"},
+						"operationName": openAPIObject{"type": "string"},
+						"variables":     openAPIObject{"type": "object"},
+					},
+					"required": []string{"query"},
+				})},
+				openAPIObject{
+					"200": response("Result of the query, errors carry the status of the REST routes in their extensions", jsonBody(openAPIObject{
+						"type": "object",
+						"properties": openAPIObject{
+							"data":   openAPIObject{"type": "object"},
+							"errors": openAPIObject{"type": "array", "items": openAPIObject{"type": "object"}},
+						},
+					})),
+					"400": errorBody("Missing query"),
+				}),
+		},
+		"/api/openapi.json": openAPIObject{
+			"get": operation("getOpenAPI", "This document", nil, nil,
+				openAPIObject{"200": response("OpenAPI document", jsonBody(openAPIObject{"type": "object"}))}),
+		},
+		"/api/docs": openAPIObject{
+			"get": operation("getDocs", "Swagger UI of this document", nil, nil,
+				openAPIObject{"200": stringBody("Swagger UI page", "text/html")}),
+		},
+		"/api/docs/{file}": openAPIObject{
+			"parameters": []openAPIObject{{"name": "file", "in": "path", "required": true,
+				"schema": openAPIObject{"type": "string", "enum": []string{"swagger-ui.css", "swagger-ui-bundle.js"}}}},
+			"get": operation("getDocsFile", "Swagger UI file of the docs page", nil, nil,
+				openAPIObject{
+					"200": stringBody("The file", "text/css", "text/javascript"),
+					"404": errorBody("No such file"),
+				}),
+		},
+	}
+
+	return openAPIObject{
+		"openapi": "3.0.3",
+		"info": openAPIObject{
+			"title":       "Meetings API",
+			"version":     openAPIVersion,
+			"description": "Schedule meetings of participants without double booking them",
+		},
+		"paths":      paths,
+		"components": openAPIObject{"schemas": schemas},
+	}
+}
+
+/*
+	function jsonSchema()
+	@params:
+		t - go type of a json value
+		inline - describe structs in place instead of referencing their entry of openAPISchemas
+	@description:
+		times are date-time strings, object ids hex strings, pointers the schema of their element
+		struct properties follow the json tags, fields without omitempty are required, embedded structs add their properties
+	@return:
+		openAPIObject - schema of t
+*/
+
+func jsonSchema(t reflect.Type, inline bool) openAPIObject {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t {
+	case reflect.TypeOf(time.Time{}):
+		return openAPIObject{"type": "string", "format": "date-time"}
+	case reflect.TypeOf(primitive.ObjectID{}):
+		return openAPIObject{"type": "string", "pattern": "^[0-9a-f]{24}$"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return openAPIObject{"type": "string"}
+	case reflect.Bool:
+		return openAPIObject{"type": "boolean"}
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return openAPIObject{"type": "integer"}
+	case reflect.Slice:
+		return openAPIObject{"type": "array", "items": jsonSchema(t.Elem(), false)}
+	case reflect.Struct:
+		for name, schemaType := range openAPISchemas {
+			if schemaType == t && !inline {
+				return ref(name)
+			}
+		}
+	default:
+		return openAPIObject{}
+	}
+
+	properties := openAPIObject{}
+	required := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := strings.Split(field.Tag.Get("json"), ",")
+		if tag[0] == "-" || field.PkgPath != "" {
+			continue
+		}
+		if field.Anonymous && tag[0] == "" {
+			// embedded structs add their fields like in encoding/json
+			embedded := jsonSchema(field.Type, true)
+			for name, property := range embedded["properties"].(openAPIObject) {
+				properties[name] = property
+			}
+			if fields, ok := embedded["required"].([]string); ok {
+				required = append(required, fields...)
+			}
+			continue
+		}
+		name := tag[0]
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = jsonSchema(field.Type, false)
+		if len(tag) == 1 || tag[1] != "omitempty" {
+			required = append(required, name)
+		}
+	}
+	schema := openAPIObject{"type": "object", "properties": properties, "additionalProperties": false}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+/*
+	function operation()
+	@params:
+		id - operationId
+		summary - summary of the route
+		parameters - query parameters, nil for none
+		body - request body, nil for none
+		responses - responses by status
+	@return:
+		openAPIObject - operation object
+*/
+
+func operation(id, summary string, parameters []openAPIObject, body openAPIObject, responses openAPIObject) openAPIObject {
+	op := openAPIObject{"operationId": id, "summary": summary, "responses": responses}
+	if parameters != nil {
+		op["parameters"] = parameters
+	}
+	if body != nil {
+		op["requestBody"] = body
+	}
+	return op
+}
+
+/*
+	function meetingResponses()
+	@params:
+		description - description of the 200 response
+	@return:
+		openAPIObject - responses of the writes of meetingErrorResponse()
+*/
+
+func meetingResponses(description string) openAPIObject {
+	return openAPIObject{
+		"200": response(description, jsonBody(ref("Meeting"))),
+		"400": errorBody("Invalid meeting"),
+		"404": errorBody("Meeting or participant not found"),
+		"409": errorBody("The meeting overlaps another meeting of a participant, or another request changed it meanwhile"),
+	}
+}
+
+func ref(name string) openAPIObject {
+	return openAPIObject{"$ref": "#/components/schemas/" + name}
+}
+
+func jsonBody(schema openAPIObject) openAPIObject {
+	return openAPIObject{"application/json": openAPIObject{"schema": schema}}
+}
+
+func response(description string, content openAPIObject) openAPIObject {
+	return openAPIObject{"description": description, "content": content}
+}
+
+func errorBody(description string) openAPIObject {
+	return response(description, jsonBody(ref("Error")))
+}
+
+func header(kind, description string) openAPIObject {
+	return openAPIObject{"description": description, "schema": openAPIObject{"type": kind}}
+}
+
+func queryParam(name, kind, description string) openAPIObject {
+	return openAPIObject{"name": name, "in": "query", "description": description, "schema": openAPIObject{"type": kind}}
+}
+
+func timeParam(name, description string) openAPIObject {
+	return openAPIObject{"name": name, "in": "query", "description": description,
+		"schema": openAPIObject{"type": "string", "format": "date-time", "example": "2020-10-19T10:00:00Z"}}
+}
+
+func enumParam(name, value, description string) openAPIObject {
+	return openAPIObject{"name": name, "in": "query", "description": description, "schema": openAPIObject{"type": "string", "enum": []string{value}}}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// outboxRetention is how long dispatched events are kept in the mongoDb outbox
+const outboxRetention = 7 * 24 * time.Hour
+
+// maxOutboxBackoff caps the wait between two attempts of a failing event
+const maxOutboxBackoff = 24 * time.Hour
+
+/*
+	type OutboxDispatcher - drains the outbox of a store
+	@attributes:
+		Store - store with the outbox
+		Handle - called with every claimed event, must return once the event is handled (or given up),
+			an error leaves the event in the outbox to be claimed again
+		Interval - wait between two polls of an empty outbox
+		Lease - time claimed events are reserved for this dispatcher, must be longer than handling a batch
+		Batch - max number of events claimed per poll
+		MaxAttempts - number of claims of an event before a failure parks it, 0 to retry forever
+		Backoff - wait before claiming a failed event again, doubled for every further attempt
+	@description:
+		An event is acked only after Handle returned, if the process dies before, the event is claimed again after
+		its lease, so events are handled at least once. The event id is the dedup key for handlers and receivers
+		An event Handle keeps failing is parked after MaxAttempts claims, so it does not block the channels forever
+*/
+
+type OutboxDispatcher struct {
+	Store       OutboxStore
+	Handle      func(Event) error
+	Interval    time.Duration
+	Lease       time.Duration
+	Batch       int
+	MaxAttempts int
+	Backoff     time.Duration
+}
+
+/*
+	function NewOutboxDispatcher()
+	@params:
+		store - store with the outbox
+		handle - called with every claimed event
+	@return:
+		*OutboxDispatcher - dispatcher polling every second with batches of 10 and a 15 minute lease,
+			long enough for all retries of the webhook deliveries of a batch,
+			retrying failed events after 1 minute, then 2, 4.. and parking them after 10 attempts
+*/
+
+func NewOutboxDispatcher(store OutboxStore, handle func(Event) error) *OutboxDispatcher {
+	return &OutboxDispatcher{
+		Store:       store,
+		Handle:      handle,
+		Interval:    time.Second,
+		Lease:       15 * time.Minute,
+		Batch:       10,
+		MaxAttempts: 10,
+		Backoff:     time.Minute,
+	}
+}
+
+/*
+	function OutboxDispatcher.Run()
+	@params:
+		stop - closing it stops the dispatcher, nil to run forever
+	@description:
+		call OutboxDispatcher.Drain() and wait Interval whenever the outbox is empty or the store fails
+*/
+
+func (dispatcher *OutboxDispatcher) Run(stop <-chan struct{}) {
+	for {
+		handled, err := dispatcher.Drain()
+		if err != nil {
+			fmt.Println(err)
+		}
+		if handled > 0 && err == nil {
+			continue
+		}
+		select {
+		case <-stop:
+			return
+		case <-time.After(dispatcher.Interval):
+		}
+	}
+}
+
+/*
+	function OutboxDispatcher.Drain()
+	@description:
+		claim one batch of events, hand each to Handle in order and ack it if Handle succeeded,
+		else record the failure, so the event is claimed again after the backoff or parked
+	@return:
+		int - number of handled and acked events
+		error - nil | last error of Handle or the store
+*/
+
+func (dispatcher *OutboxDispatcher) Drain() (int, error) {
+	events, err := dispatcher.Store.ClaimEvents(dispatcher.Batch, dispatcher.Lease)
+	if err != nil {
+		return 0, err
+	}
+
+	handled := 0
+	for _, event := range events {
+		if handleErr := dispatcher.Handle(event); handleErr != nil {
+			err = handleErr
+			if failErr := dispatcher.Store.FailEvent(event.ID, handleErr.Error(), dispatcher.Backoff, dispatcher.MaxAttempts); failErr != nil {
+				return handled, failErr
+			}
+			continue
+		}
+		if ackErr := dispatcher.Store.AckEvent(event.ID); ackErr != nil {
+			return handled, ackErr
+		}
+		handled++
+	}
+	return handled, err
+}
+
+/*
+	function retryDelay()
+	@params:
+		backoff - wait after the first attempt
+		attempts - number of attempts so far
+	@return:
+		time.Duration - backoff doubled for every attempt after the first, at most maxOutboxBackoff
+*/
+
+func retryDelay(backoff time.Duration, attempts int) time.Duration {
+	delay := backoff
+	for i := 1; i < attempts && delay < maxOutboxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxOutboxBackoff {
+		delay = maxOutboxBackoff
+	}
+	return delay
+}
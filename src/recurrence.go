@@ -0,0 +1,291 @@
+package main
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dxmxnlord/golang-api/src/model"
+)
+
+// recurrenceHorizon bounds the overlap check of a series without COUNT or UNTIL, conflicts further after its start are not found
+const recurrenceHorizon = 2 * 365 * 24 * time.Hour
+
+// Override changes one occurrence of a recurring meeting, see model.Override
+type Override = model.Override
+
+/*
+	function Meeting.validateRecurrence()
+	@purpose:
+		check the recurrence fields of a meeting
+	@description:
+		perform checks:
+			exceptions and overrides need a recurrence rule
+			the rule must parse
+			every exception and override must be an occurrence of the rule
+			overrides must start before they end and not before the series
+	@return:
+		error - nil | error
+*/
+
+func (meeting *Meeting) validateRecurrence() error {
+	if meeting.Recurrence == "" {
+		if len(meeting.Exceptions) > 0 || len(meeting.Overrides) > 0 {
+			return errors.New("Exceptions and overrides need a recurrence rule")
+		}
+		return nil
+	}
+
+	rule, err := parseRRule(meeting.Recurrence)
+	if err != nil {
+		return err
+	}
+
+	for _, exception := range meeting.Exceptions {
+		if !rule.includes(meeting.StartTime, exception) {
+			return errors.New("Exception is not an occurrence of the recurrence")
+		}
+	}
+	for _, override := range meeting.Overrides {
+		if !rule.includes(meeting.StartTime, override.RecurrenceID) {
+			return errors.New("Override is not an occurrence of the recurrence")
+		}
+		if override.EndTime.Before(override.StartTime) {
+			return errors.New("Start time is not before End time")
+		}
+		if override.StartTime.Before(meeting.StartTime) {
+			return errors.New("Override starts before the series")
+		}
+	}
+	return nil
+}
+
+/*
+	function RRule.includes()
+	@params:
+		dtstart - start of the first occurrence
+		start - start to look for
+	@return:
+		bool - true if the rule generates an occurrence starting at start
+*/
+
+func (rule *RRule) includes(dtstart, start time.Time) bool {
+	found := false
+	rule.each(dtstart, start, func(occurrence time.Time) bool {
+		found = occurrence.Equal(start)
+		return !found
+	})
+	return found
+}
+
+/*
+	function Meeting.prepareRecurrence()
+	@description:
+		normalize the recurrence rule and set SeriesEnd for series with COUNT or UNTIL, the stores query series by it
+		must be called after Meeting.validateRecurrence()
+*/
+
+func (meeting *Meeting) prepareRecurrence() {
+	meeting.SeriesEnd = nil
+	if meeting.Recurrence == "" {
+		return
+	}
+	meeting.Recurrence = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(meeting.Recurrence)), "RRULE:")
+
+	rule, _ := parseRRule(meeting.Recurrence)
+	if rule.Count == 0 && rule.Until.IsZero() {
+		return
+	}
+
+	var last time.Time
+	rule.each(meeting.StartTime, maxTime, func(start time.Time) bool {
+		last = start
+		return true
+	})
+	end := last.Add(meeting.EndTime.Sub(meeting.StartTime))
+	for _, override := range meeting.Overrides {
+		if override.EndTime.After(end) {
+			end = override.EndTime
+		}
+	}
+	meeting.SeriesEnd = &end
+}
+
+// maxTime is used as the bound when expanding a series with COUNT or UNTIL completely
+var maxTime = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+
+/*
+	function Meeting.checkWindow()
+	@return:
+		time.Time, time.Time - the time range in which meeting can overlap other meetings
+	@description:
+		a single meeting covers its own times, a series runs until SeriesEnd or recurrenceHorizon after its start
+*/
+
+func (meeting *Meeting) checkWindow() (time.Time, time.Time) {
+	if meeting.Recurrence == "" {
+		return meeting.StartTime, meeting.EndTime
+	}
+	if meeting.SeriesEnd != nil {
+		return meeting.StartTime, *meeting.SeriesEnd
+	}
+	return meeting.StartTime, meeting.StartTime.Add(recurrenceHorizon)
+}
+
+/*
+	function Meeting.spans()
+	@params:
+		from, to - time range
+	@return:
+		bool - true if the meeting, or any occurrence of its series, can intersect from and to
+*/
+
+func (meeting *Meeting) spans(from, to time.Time) bool {
+	if !meeting.StartTime.Before(to) {
+		return false
+	}
+	if meeting.Recurrence == "" {
+		return meeting.EndTime.After(from)
+	}
+	return meeting.SeriesEnd == nil || meeting.SeriesEnd.After(from)
+}
+
+/*
+	function Meeting.occurrences()
+	@params:
+		from, to - time range
+	@description:
+		A single meeting is its only occurrence.
+		For a series generate the starts of the rule before to, leave out exceptions and overridden starts,
+		then add the overrides. Each occurrence is a copy of the series with its own times and RecurrenceID set
+		and without the recurrence fields.
+	@return:
+		[]Meeting - occurrences intersecting from and to, sorted by start time
+*/
+
+func (meeting *Meeting) occurrences(from, to time.Time) []Meeting {
+	if meeting.Recurrence == "" {
+		if meeting.StartTime.Before(to) && meeting.EndTime.After(from) {
+			return []Meeting{copyMeeting(*meeting)}
+		}
+		return nil
+	}
+
+	rule, err := parseRRule(meeting.Recurrence)
+	if err != nil {
+		return nil
+	}
+
+	skipped := map[int64]bool{}
+	for _, exception := range meeting.Exceptions {
+		skipped[exception.UnixNano()] = true
+	}
+	overridden := map[int64]bool{}
+	for _, override := range meeting.Overrides {
+		overridden[override.RecurrenceID.UnixNano()] = true
+	}
+
+	var occurrences []Meeting
+	occurrence := func(id, start, end time.Time, title string) {
+		if start.Before(to) && end.After(from) {
+			instance := copyMeeting(*meeting)
+			instance.StartTime, instance.EndTime, instance.Title = start, end, title
+			instance.RecurrenceID = &id
+			instance.Recurrence, instance.Exceptions, instance.Overrides, instance.SeriesEnd = "", nil, nil, nil
+			occurrences = append(occurrences, instance)
+		}
+	}
+
+	duration := meeting.EndTime.Sub(meeting.StartTime)
+	rule.each(meeting.StartTime, to, func(start time.Time) bool {
+		if !skipped[start.UnixNano()] && !overridden[start.UnixNano()] {
+			occurrence(start, start, start.Add(duration), meeting.Title)
+		}
+		return true
+	})
+	for _, override := range meeting.Overrides {
+		if skipped[override.RecurrenceID.UnixNano()] {
+			continue
+		}
+		title := override.Title
+		if title == "" {
+			title = meeting.Title
+		}
+		occurrence(override.RecurrenceID, override.StartTime, override.EndTime, title)
+	}
+
+	sortMeetings(occurrences)
+	return occurrences
+}
+
+/*
+	function Meeting.conflictsWith()
+	@params:
+		other - meeting already booked by a participant of meeting
+	@description:
+		expand both meetings in the check window of meeting and sweep over all occurrences by start time,
+		remembering the latest end seen of each meeting. An occurrence starting before the latest end of the other meeting overlaps it.
+	@return:
+		bool - true if an occurrence of meeting overlaps an occurrence of other
+*/
+
+func (meeting *Meeting) conflictsWith(other Meeting) bool {
+	from, to := meeting.checkWindow()
+	if to.Equal(from) {
+		// an empty meeting still blocks the meetings around it
+		to = to.Add(time.Nanosecond)
+	}
+
+	type interval struct {
+		start, end time.Time
+		own bool
+	}
+	var intervals []interval
+	for _, occurrence := range meeting.occurrences(from, to) {
+		intervals = append(intervals, interval{occurrence.StartTime, occurrence.EndTime, true})
+	}
+	if meeting.Recurrence == "" && len(intervals) == 0 {
+		intervals = append(intervals, interval{meeting.StartTime, meeting.EndTime, true})
+	}
+	for _, occurrence := range other.occurrences(from, to) {
+		intervals = append(intervals, interval{occurrence.StartTime, occurrence.EndTime, false})
+	}
+	sort.SliceStable(intervals, func(i, j int) bool { return intervals[i].start.Before(intervals[j].start) })
+
+	var ownEnd, otherEnd time.Time
+	for _, current := range intervals {
+		if current.own {
+			if otherEnd.After(current.start) {
+				return true
+			}
+			if current.end.After(ownEnd) {
+				ownEnd = current.end
+			}
+		} else {
+			if ownEnd.After(current.start) {
+				return true
+			}
+			if current.end.After(otherEnd) {
+				otherEnd = current.end
+			}
+		}
+	}
+	return false
+}
+
+/*
+	function mergeMeetings()
+	@params:
+		a, b - meetings sorted by start time and id
+	@return:
+		[]Meeting - all meetings of a and b sorted by start time and id
+*/
+
+func mergeMeetings(a, b []Meeting) []Meeting {
+	merged := make([]Meeting, 0, len(a)+len(b))
+	merged = append(merged, a...)
+	merged = append(merged, b...)
+	sortMeetings(merged)
+	return merged
+}
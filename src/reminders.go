@@ -0,0 +1,243 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// maxReminders is the number of reminders one meeting may have
+const maxReminders = 5
+
+// maxReminderMinutes is the earliest a reminder may fire before a meeting, four weeks
+const maxReminderMinutes = 4 * 7 * 24 * 60
+
+/*
+	function Meeting.validateReminders()
+	@description:
+		perform checks:
+			at most maxReminders reminders
+			every reminder is between 0 and maxReminderMinutes minutes before the start
+			every reminder is used only once
+	@return:
+		error - nil | error
+*/
+
+func (meeting *Meeting) validateReminders() error {
+	if len(meeting.Reminders) > maxReminders {
+		return errors.New("Too many reminders")
+	}
+	minutes := map[int]bool{}
+	for _, reminder := range meeting.Reminders {
+		if reminder < 0 || reminder > maxReminderMinutes {
+			return errors.New("Invalid reminder")
+		}
+		if minutes[reminder] {
+			return errors.New("Repeated reminder")
+		}
+		minutes[reminder] = true
+	}
+	return nil
+}
+
+/*
+	function Meeting.prepareReminders()
+	@params:
+		now - time of the write
+	@description:
+		set NextReminder to the first reminder firing after now, so the stores can find due reminders with an index
+		and every write of the meeting reschedules them
+*/
+
+func (meeting *Meeting) prepareReminders(now time.Time) {
+	meeting.NextReminder = meeting.nextReminder(now)
+}
+
+/*
+	function Meeting.nextReminder()
+	@params:
+		after - time the reminder must fire after
+	@description:
+		look for occurrences in a window of one day after the largest reminder and double the window
+		until a reminder fires inside it or the window reaches recurrenceHorizon.
+		Occurrences after the window fire after its end, so the earliest reminder inside it is the next one
+	@return:
+		*time.Time - time of the next reminder, nil if the meeting is cancelled or has none left
+*/
+
+func (meeting *Meeting) nextReminder(after time.Time) *time.Time {
+	if len(meeting.Reminders) == 0 || meeting.Status == StatusCancelled {
+		return nil
+	}
+	if meeting.Recurrence == "" && !meeting.StartTime.After(after) {
+		return nil
+	}
+	if meeting.SeriesEnd != nil && !meeting.SeriesEnd.After(after) {
+		return nil
+	}
+
+	largest := 0
+	for _, reminder := range meeting.Reminders {
+		if reminder > largest {
+			largest = reminder
+		}
+	}
+	lead := time.Duration(largest) * time.Minute
+
+	for window := 24 * time.Hour; ; window *= 2 {
+		var next *time.Time
+		for _, occurrence := range meeting.occurrences(after, after.Add(lead+window)) {
+			for _, reminder := range meeting.Reminders {
+				fire := occurrence.StartTime.Add(-time.Duration(reminder) * time.Minute)
+				if fire.After(after) && (next == nil || fire.Before(*next)) {
+					next = &fire
+				}
+			}
+		}
+		if (next != nil && !next.After(after.Add(window))) || window >= recurrenceHorizon {
+			return next
+		}
+	}
+}
+
+/*
+	function Meeting.reminded()
+	@return:
+		[]string - emails of the participants who get reminders, those with rsvp Yes or Maybe
+*/
+
+func (meeting *Meeting) reminded() []string {
+	var emails []string
+	for _, participant := range meeting.Participants {
+		if participant.RSVP == "Yes" || participant.RSVP == "Maybe" {
+			emails = append(emails, participant.Email)
+		}
+	}
+	return emails
+}
+
+/*
+	function Meeting.reminderEvents()
+	@params:
+		from - time of the first due reminder, the NextReminder of the meeting
+		now - current time
+	@description:
+		A reminder is due if it fires between from and now and its occurrence has not started yet,
+		so reminders missed while no scheduler was running are sent late, but never after the meeting started
+	@return:
+		[]Event - one meeting.reminder event per due reminder with the occurrence and the participants to remind,
+			none if nobody has rsvp Yes or Maybe
+*/
+
+func (meeting *Meeting) reminderEvents(from, now time.Time) []Event {
+	var events []Event
+	recipients := meeting.reminded()
+	if len(recipients) == 0 {
+		return events
+	}
+
+	for _, occurrence := range meeting.occurrences(now, now.Add(maxReminderMinutes*time.Minute)) {
+		if !occurrence.StartTime.After(now) {
+			continue
+		}
+		for _, reminder := range meeting.Reminders {
+			fire := occurrence.StartTime.Add(-time.Duration(reminder) * time.Minute)
+			if !fire.Before(from) && !fire.After(now) {
+				event := Event{Type: EventMeetingReminder, Minutes: reminder, Recipients: recipients}
+				occurrence.NextReminder = nil
+				events = append(events, event.of(&occurrence))
+			}
+		}
+	}
+	return events
+}
+
+/*
+	type ReminderScheduler - turns due reminders into meeting.reminder events
+	@attributes:
+		Store - store with the meetings and the outbox
+		Interval - wait between two looks for due reminders
+		Batch - max number of meetings handled per look
+	@description:
+		The events go to the outbox like the events of meeting writes, so they reach every channel of `Api.publish()`
+		with the same retries. The store moves NextReminder and records the events in one atomic step and only
+		if NextReminder was not moved since it was read, so schedulers of several Api instances can run at the same
+		time and every reminder is recorded once, without electing a leader
+*/
+
+type ReminderScheduler struct {
+	Store    ReminderStore
+	Interval time.Duration
+	Batch    int
+}
+
+/*
+	function NewReminderScheduler()
+	@params:
+		store - store with the meetings and the outbox
+	@return:
+		*ReminderScheduler - scheduler looking every 10 seconds for at most 100 meetings
+*/
+
+func NewReminderScheduler(store ReminderStore) *ReminderScheduler {
+	return &ReminderScheduler{
+		Store:    store,
+		Interval: 10 * time.Second,
+		Batch:    100,
+	}
+}
+
+/*
+	function ReminderScheduler.Run()
+	@params:
+		stop - closing it stops the scheduler, nil to run forever
+	@description:
+		call ReminderScheduler.Fire() every Interval
+*/
+
+func (scheduler *ReminderScheduler) Run(stop <-chan struct{}) {
+	for {
+		if _, err := scheduler.Fire(time.Now()); err != nil {
+			fmt.Println(err)
+		}
+		select {
+		case <-stop:
+			return
+		case <-time.After(scheduler.Interval):
+		}
+	}
+}
+
+/*
+	function ReminderScheduler.Fire()
+	@params:
+		now - current time
+	@description:
+		for every meeting with a due NextReminder collect the due reminder events and the following reminder,
+		then let the store record them. A meeting written or fired by someone else in between is left for the next look
+	@return:
+		int - number of recorded reminder events
+		error - nil | last error of the store
+*/
+
+func (scheduler *ReminderScheduler) Fire(now time.Time) (int, error) {
+	meetings, err := scheduler.Store.DueReminders(now, scheduler.Batch)
+	if err != nil {
+		return 0, err
+	}
+
+	fired := 0
+	for i := range meetings {
+		meeting := &meetings[i]
+		events := meeting.reminderEvents(*meeting.NextReminder, now)
+		ok, fireErr := scheduler.Store.FireReminder(meeting, meeting.nextReminder(now), events)
+		if fireErr != nil {
+			err = fireErr
+			continue
+		}
+		if ok {
+			fired += len(events)
+		}
+	}
+	return fired, err
+}
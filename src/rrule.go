@@ -0,0 +1,309 @@
+package main
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxRulePeriods stops the expansion of rules which never or rarely produce an occurrence
+const maxRulePeriods = 100000
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+/*
+	type RuleDay - one BYDAY entry of a recurrence rule
+	@attributes:
+		N - 0 for every such weekday, n for the nth and -n for the nth last one of the month (MONTHLY only)
+		Day - the weekday
+*/
+
+type RuleDay struct {
+	N   int
+	Day time.Weekday
+}
+
+/*
+	type RRule - the supported subset of an RFC 5545 recurrence rule
+	@attributes:
+		Freq - DAILY, WEEKLY or MONTHLY
+		Interval - number of periods between occurrences, 1 by default
+		Count - number of occurrences, 0 for no limit
+		Until - last possible start of an occurrence, zero for no limit
+		ByDay - weekdays of the occurrences
+	@description:
+		Weeks start on monday and all days are computed in the location of the first occurrence (UTC for the Api)
+*/
+
+type RRule struct {
+	Freq     string
+	Interval int
+	Count    int
+	Until    time.Time
+	ByDay    []RuleDay
+}
+
+/*
+	function parseRRule()
+	@params:
+		text - rule like FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10, optionally prefixed with RRULE:
+	@return:
+		*RRule - parsed rule
+		error - nil | error naming the invalid or unsupported part
+*/
+
+func parseRRule(text string) (*RRule, error) {
+	rule := &RRule{Interval: 1}
+	text = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(text)), "RRULE:")
+
+	for _, part := range strings.Split(text, ";") {
+		pair := strings.SplitN(part, "=", 2)
+		if len(pair) != 2 || pair[1] == "" {
+			return nil, errors.New("Invalid recurrence rule part " + part)
+		}
+		key, value := pair[0], pair[1]
+
+		var err error
+		switch key {
+		case "FREQ":
+			if value != "DAILY" && value != "WEEKLY" && value != "MONTHLY" {
+				return nil, errors.New("Unsupported recurrence frequency " + value)
+			}
+			rule.Freq = value
+		case "INTERVAL":
+			rule.Interval, err = strconv.Atoi(value)
+			if err != nil || rule.Interval < 1 {
+				return nil, errors.New("Invalid recurrence interval " + value)
+			}
+		case "COUNT":
+			rule.Count, err = strconv.Atoi(value)
+			if err != nil || rule.Count < 1 {
+				return nil, errors.New("Invalid recurrence count " + value)
+			}
+		case "UNTIL":
+			rule.Until, err = parseRuleTime(value)
+			if err != nil {
+				return nil, errors.New("Invalid recurrence until " + value)
+			}
+		case "BYDAY":
+			for _, entry := range strings.Split(value, ",") {
+				day, err := parseRuleDay(entry)
+				if err != nil {
+					return nil, err
+				}
+				rule.ByDay = append(rule.ByDay, day)
+			}
+		case "WKST":
+			if value != "MO" {
+				return nil, errors.New("Unsupported recurrence week start " + value)
+			}
+		default:
+			return nil, errors.New("Unsupported recurrence rule part " + key)
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, errors.New("Missing recurrence frequency")
+	}
+	if rule.Count > 0 && !rule.Until.IsZero() {
+		return nil, errors.New("Recurrence can not have both COUNT and UNTIL")
+	}
+	for _, day := range rule.ByDay {
+		if day.N != 0 && rule.Freq != "MONTHLY" {
+			return nil, errors.New("Numbered BYDAY is only supported for MONTHLY recurrences")
+		}
+	}
+	return rule, nil
+}
+
+/*
+	function parseRuleTime()
+	@params:
+		value - UNTIL value as date (20060102), UTC date time (20060102T150405Z) or RFC 3339
+	@return:
+		time.Time - parsed time, a date means the end of that day
+		error - nil | error
+*/
+
+func parseRuleTime(value string) (time.Time, error) {
+	if t, err := time.Parse("20060102T150405Z", value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("20060102", value); err == nil {
+		return t.Add(24*time.Hour - time.Second), nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+/*
+	function parseRuleDay()
+	@params:
+		entry - BYDAY entry like MO, 1MO or -1FR
+	@return:
+		RuleDay - parsed entry
+		error - nil | error
+*/
+
+func parseRuleDay(entry string) (RuleDay, error) {
+	if len(entry) < 2 {
+		return RuleDay{}, errors.New("Invalid recurrence day " + entry)
+	}
+	day, ok := weekdays[entry[len(entry)-2:]]
+	if !ok {
+		return RuleDay{}, errors.New("Invalid recurrence day " + entry)
+	}
+
+	n := 0
+	if prefix := entry[:len(entry)-2]; prefix != "" {
+		var err error
+		n, err = strconv.Atoi(prefix)
+		if err != nil || n == 0 || n > 5 || n < -5 {
+			return RuleDay{}, errors.New("Invalid recurrence day " + entry)
+		}
+	}
+	return RuleDay{N: n, Day: day}, nil
+}
+
+/*
+	function RRule.each()
+	@params:
+		dtstart - start of the first occurrence
+		bound - stop at the first occurrence starting after bound
+		fn - called with the start of each occurrence in order, returning false stops the expansion
+	@description:
+		walk the periods (days, weeks or months) of the rule from dtstart and call fn for each occurrence
+		COUNT counts every occurrence from dtstart, UNTIL and bound are inclusive
+*/
+
+func (rule *RRule) each(dtstart, bound time.Time, fn func(time.Time) bool) {
+	count := 0
+	for period := 0; period < maxRulePeriods; period++ {
+		for _, start := range rule.period(dtstart, period) {
+			if start.Before(dtstart) {
+				continue
+			}
+			if (!rule.Until.IsZero() && start.After(rule.Until)) || start.After(bound) {
+				return
+			}
+			count++
+			if !fn(start) || (rule.Count > 0 && count >= rule.Count) {
+				return
+			}
+		}
+	}
+}
+
+/*
+	function RRule.period()
+	@params:
+		dtstart - start of the first occurrence
+		n - index of the period from the one containing dtstart
+	@return:
+		[]time.Time - sorted candidate starts in the nth period, at the time of day of dtstart
+*/
+
+func (rule *RRule) period(dtstart time.Time, n int) []time.Time {
+	at := func(day time.Time) time.Time {
+		return time.Date(day.Year(), day.Month(), day.Day(),
+			dtstart.Hour(), dtstart.Minute(), dtstart.Second(), dtstart.Nanosecond(), dtstart.Location())
+	}
+	var starts []time.Time
+
+	switch rule.Freq {
+	case "DAILY":
+		day := dtstart.AddDate(0, 0, n*rule.Interval)
+		if len(rule.ByDay) == 0 || rule.hasWeekday(day.Weekday()) {
+			starts = append(starts, day)
+		}
+
+	case "WEEKLY":
+		monday := dtstart.AddDate(0, 0, -mondayOffset(dtstart.Weekday())+7*n*rule.Interval)
+		if len(rule.ByDay) == 0 {
+			starts = append(starts, monday.AddDate(0, 0, mondayOffset(dtstart.Weekday())))
+		}
+		for _, day := range rule.ByDay {
+			starts = append(starts, at(monday.AddDate(0, 0, mondayOffset(day.Day))))
+		}
+
+	case "MONTHLY":
+		first := time.Date(dtstart.Year(), dtstart.Month()+time.Month(n*rule.Interval), 1, 0, 0, 0, 0, dtstart.Location())
+		last := first.AddDate(0, 1, -1)
+		if len(rule.ByDay) == 0 {
+			// months without this day of month are skipped
+			if dtstart.Day() <= last.Day() {
+				starts = append(starts, at(first.AddDate(0, 0, dtstart.Day()-1)))
+			}
+		}
+		for _, day := range rule.ByDay {
+			var matching []time.Time
+			for d := first; !d.After(last); d = d.AddDate(0, 0, 1) {
+				if d.Weekday() == day.Day {
+					matching = append(matching, at(d))
+				}
+			}
+			if day.N == 0 {
+				starts = append(starts, matching...)
+			} else if day.N > 0 && day.N <= len(matching) {
+				starts = append(starts, matching[day.N-1])
+			} else if day.N < 0 && -day.N <= len(matching) {
+				starts = append(starts, matching[len(matching)+day.N])
+			}
+		}
+	}
+
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+	return dedupeTimes(starts)
+}
+
+/*
+	function RRule.hasWeekday()
+	@return:
+		bool - true if the BYDAY list of the rule contains weekday
+*/
+
+func (rule *RRule) hasWeekday(weekday time.Weekday) bool {
+	for _, day := range rule.ByDay {
+		if day.Day == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+	function mondayOffset()
+	@return:
+		int - days from monday to weekday
+*/
+
+func mondayOffset(weekday time.Weekday) int {
+	return (int(weekday) + 6) % 7
+}
+
+/*
+	function dedupeTimes()
+	@params:
+		times - sorted times
+	@return:
+		[]time.Time - times without repeated entries
+*/
+
+func dedupeTimes(times []time.Time) []time.Time {
+	var unique []time.Time
+	for _, t := range times {
+		if len(unique) == 0 || !unique[len(unique)-1].Equal(t) {
+			unique = append(unique, t)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func expandRule(t *testing.T, text string, dtstart time.Time, bound time.Time) []string {
+	rule, err := parseRRule(text)
+	if err != nil {
+		t.Fatalf("Could not parse '%s': %v", text, err)
+	}
+	var starts []string
+	rule.each(dtstart, bound, func(start time.Time) bool {
+		starts = append(starts, start.Format("2006-01-02 Mon 15:04"))
+		return true
+	})
+	return starts
+}
+
+func checkStarts(t *testing.T, expect, actual []string) {
+	if len(expect) != len(actual) {
+		t.Errorf("Expected %v. Got %v instead", expect, actual)
+		return
+	}
+	for i := range expect {
+		if expect[i] != actual[i] {
+			t.Errorf("Expected %v. Got %v instead", expect, actual)
+			return
+		}
+	}
+}
+
+func TestRRuleWeeklyByDayCount(t *testing.T) {
+	dtstart := time.Date(2020, 10, 19, 10, 0, 0, 0, time.UTC)
+	checkStarts(t, []string{
+		"2020-10-19 Mon 10:00",
+		"2020-10-21 Wed 10:00",
+		"2020-10-26 Mon 10:00",
+		"2020-10-28 Wed 10:00",
+		"2020-11-02 Mon 10:00",
+	}, expandRule(t, "RRULE:FREQ=WEEKLY;BYDAY=MO,WE;COUNT=5", dtstart, maxTime))
+}
+
+func TestRRuleDailyIntervalUntil(t *testing.T) {
+	dtstart := time.Date(2020, 10, 30, 9, 30, 0, 0, time.UTC)
+	checkStarts(t, []string{
+		"2020-10-30 Fri 09:30",
+		"2020-11-01 Sun 09:30",
+		"2020-11-03 Tue 09:30",
+	}, expandRule(t, "FREQ=DAILY;INTERVAL=2;UNTIL=20201103T093000Z", dtstart, maxTime))
+}
+
+func TestRRuleDailyWeekdaysBound(t *testing.T) {
+	dtstart := time.Date(2020, 10, 22, 9, 0, 0, 0, time.UTC)
+	checkStarts(t, []string{
+		"2020-10-22 Thu 09:00",
+		"2020-10-23 Fri 09:00",
+		"2020-10-26 Mon 09:00",
+	}, expandRule(t, "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR", dtstart, time.Date(2020, 10, 26, 9, 0, 0, 0, time.UTC)))
+}
+
+func TestRRuleMonthly(t *testing.T) {
+	dtstart := time.Date(2020, 10, 30, 16, 0, 0, 0, time.UTC)
+	checkStarts(t, []string{
+		"2020-10-30 Fri 16:00",
+		"2020-11-27 Fri 16:00",
+		"2020-12-25 Fri 16:00",
+	}, expandRule(t, "FREQ=MONTHLY;BYDAY=-1FR;COUNT=3", dtstart, maxTime))
+
+	// months without a 31st are skipped
+	dtstart = time.Date(2020, 10, 31, 16, 0, 0, 0, time.UTC)
+	checkStarts(t, []string{
+		"2020-10-31 Sat 16:00",
+		"2020-12-31 Thu 16:00",
+		"2021-01-31 Sun 16:00",
+	}, expandRule(t, "FREQ=MONTHLY;COUNT=3", dtstart, maxTime))
+}
+
+func TestRRuleInvalid(t *testing.T) {
+	for _, text := range []string{
+		"FREQ=YEARLY",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=DAILY;COUNT=2;UNTIL=20201103",
+		"FREQ=DAILY;BYHOUR=9",
+		"COUNT=3",
+	} {
+		if _, err := parseRRule(text); err == nil {
+			t.Errorf("Expected '%s' to be rejected", text)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// maxSlotCandidates limits the slot starts one find-a-time request may test
+const maxSlotCandidates = 20000
+
+// ErrTooManySlots is returned by findSlots() when the window holds more than maxSlotCandidates steps
+var ErrTooManySlots = errors.New("Too many slots in the window, narrow the window or use a larger step")
+
+/*
+	type SlotRequest - describes a find-a-time request
+	@attributes:
+		Emails - participants who all have to be free
+		Duration - length of the meeting
+		Step - distance between candidate starts, starts are multiples of Step
+		Start, End - search window
+		WorkStart, WorkEnd - working hours as wall clock times (hours and minutes after midnight) in Location, both zero for the whole day
+		Weekdays - allowed days in Location, all days if empty
+		Location - time zone of the working hours and weekdays
+		Limit - max number of slots to return
+*/
+
+type SlotRequest struct {
+	Emails    []string
+	Duration  time.Duration
+	Step      time.Duration
+	Start     time.Time
+	End       time.Time
+	WorkStart time.Duration
+	WorkEnd   time.Duration
+	Weekdays  map[time.Weekday]bool
+	Location  *time.Location
+	Limit     int
+}
+
+/*
+	type Slot - a suggested meeting time
+	@attributes:
+		Start, End - times of the slot
+		MaybeConflicts - participants with an rsvp Maybe meeting during the slot
+*/
+
+type Slot struct {
+	Start          time.Time `json:"start"`
+	End            time.Time `json:"end"`
+	MaybeConflicts []string  `json:"maybe_conflicts"`
+}
+
+/*
+	function findSlots()
+	@purpose:
+		suggest times at which all participants are free
+	@params:
+		store - MeetingStore instance
+		request - participants, duration, window and constraints
+	@description:
+		refuse windows with more than maxSlotCandidates slot starts instead of leaving out their end
+		get the busy intervals of every participant with getBusy(), once for rsvp Yes and once including Maybe
+		walk the window in steps and keep the slots which:
+			lie inside the working hours and allowed weekdays
+			do not overlap any rsvp Yes meeting, with the same semantics as the overlap check of Meeting.createMeeting()
+		rank slots with fewer participants having a Maybe meeting at that time first, then earlier slots first
+	@return:
+		[]Slot - at most request.Limit ranked slots
+		error - nil | ErrTooManySlots | error
+*/
+
+func findSlots(store MeetingStore, request SlotRequest) ([]Slot, error) {
+	start := request.Start.Truncate(request.Step)
+	if start.Before(request.Start) {
+		start = start.Add(request.Step)
+	}
+	if (request.End.Sub(start)-request.Duration)/request.Step >= maxSlotCandidates {
+		return nil, ErrTooManySlots
+	}
+
+	var yes []Interval
+	maybe := map[string][]Interval{}
+	for _, email := range request.Emails {
+		busy, err := getBusy(store, email, request.Start, request.End, false)
+		if err != nil {
+			return nil, err
+		}
+		yes = append(yes, busy...)
+
+		if maybe[email], err = getBusy(store, email, request.Start, request.End, true); err != nil {
+			return nil, err
+		}
+	}
+	yes = mergeIntervals(yes)
+
+	slots := []Slot{}
+	for tested := 0; ; tested++ {
+		slot := Interval{Start: start.Add(time.Duration(tested) * request.Step)}
+		slot.End = slot.Start.Add(request.Duration)
+		if slot.End.After(request.End) {
+			break
+		}
+		if !request.allows(slot) || intersectsAny(slot, yes) {
+			continue
+		}
+
+		conflicts := []string{}
+		for _, email := range request.Emails {
+			if intersectsAny(slot, maybe[email]) {
+				conflicts = append(conflicts, email)
+			}
+		}
+		slots = append(slots, Slot{Start: slot.Start, End: slot.End, MaybeConflicts: conflicts})
+	}
+
+	sort.SliceStable(slots, func(i, j int) bool {
+		return len(slots[i].MaybeConflicts) < len(slots[j].MaybeConflicts)
+	})
+	if len(slots) > request.Limit {
+		slots = slots[:request.Limit]
+	}
+	return slots, nil
+}
+
+/*
+	function SlotRequest.allows()
+	@params:
+		slot - candidate slot
+	@return:
+		bool - true if slot lies within one working day on an allowed weekday
+*/
+
+func (request *SlotRequest) allows(slot Interval) bool {
+	start := slot.Start.In(request.Location)
+	if len(request.Weekdays) > 0 && !request.Weekdays[start.Weekday()] {
+		return false
+	}
+	if request.WorkStart == 0 && request.WorkEnd == 0 {
+		return true
+	}
+	return !start.Before(request.clock(start, request.WorkStart)) && !slot.End.After(request.clock(start, request.WorkEnd))
+}
+
+/*
+	function SlotRequest.clock()
+	@params:
+		day - time on the day in Location
+		offset - wall clock time as hours and minutes after midnight
+	@description:
+		build the time with time.Date() instead of adding offset to midnight, which is an hour off on daylight saving days
+	@return:
+		time.Time - the wall clock time on day
+*/
+
+func (request *SlotRequest) clock(day time.Time, offset time.Duration) time.Time {
+	hour, minute := int(offset/time.Hour), int(offset%time.Hour/time.Minute)
+	return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, request.Location)
+}
+
+/*
+	function intersectsAny()
+	@params:
+		slot - candidate slot
+		intervals - sorted busy intervals
+	@return:
+		bool - true if slot starts before the end and ends after the start of any interval
+*/
+
+func intersectsAny(slot Interval, intervals []Interval) bool {
+	for _, interval := range intervals {
+		if !interval.Start.Before(slot.End) {
+			return false
+		}
+		if interval.End.After(slot.Start) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrMeetingNotFound is returned by a MeetingStore when no meeting matches the requested id
+var ErrMeetingNotFound = errors.New("Meeting not found")
+
+// ErrParticipantNotFound is returned when a meeting has no participant with the requested email
+var ErrParticipantNotFound = errors.New("Participant not found")
+
+// ErrStatusChange is returned when an update changes the status of a meeting instead of cancelling it
+var ErrStatusChange = errors.New("Status can not be updated, use POST /api/meetings/{id}/cancel to cancel a meeting")
+
+// ErrMeetingChanged is returned by MeetingStore.UpdateMeeting when the meeting was written by someone else since it was read
+var ErrMeetingChanged = errors.New("Meeting was changed by another request, retry")
+
+/*
+	type OverlapError - returned when a participant going for a meeting already has a meeting at that time
+	@attributes:
+		Email - email of the double booked participant
+*/
+
+type OverlapError struct {
+	Email string
+}
+
+func (err *OverlapError) Error() string {
+	return "Overlapping meeting for email " + err.Email
+}
+
+/*
+	interface MeetingStore - describes the storage backend of the application
+	@methods:
+		InsertMeeting - atomically check that no participant going for the meeting is double booked and insert it, *OverlapError otherwise
+			event is completed with Event.of() for the written meeting and added to the outbox in the same atomic step
+		UpdateMeeting - like InsertMeeting but replaces the meeting with the same ID, which is left out of its own overlap check
+			only if the stored Meeting.Version is still the one of meeting, ErrMeetingChanged otherwise. The write increments
+			Meeting.Version, so of two writes of the same read one fails instead of silently losing the other
+			the event is completed with Event.replacing() for the replaced and the written meeting
+		DeleteMeeting - remove a meeting by id, ErrMeetingNotFound if it does not exist
+			event is completed with Event.of() for the removed meeting and added to the outbox in the same atomic step
+		GetMeeting - get a single meeting by id, ErrMeetingNotFound if it does not exist
+		FindOverlapping - get active meetings where the participant with email has rsvp Yes (or Maybe with includeMaybe)
+			and an occurrence overlaps start and end
+		FindMeetings - get one page of the meetings matching a MeetingQuery, ordered by start time and id, and the total number of matches
+	@description:
+		The Api only talks to storage through this interface.
+		MongoStore keeps meetings in a mongoDb collection and MemoryStore keeps them in process memory
+		The overlap check and the write of InsertMeeting and UpdateMeeting must not interleave with another write for the same participant,
+		even when several Api instances share the store
+*/
+
+type MeetingStore interface {
+	InsertMeeting(meeting *Meeting, event Event) (primitive.ObjectID, error)
+	UpdateMeeting(meeting *Meeting, event Event) error
+	DeleteMeeting(id primitive.ObjectID, event Event) error
+	GetMeeting(id primitive.ObjectID) (Meeting, error)
+	FindOverlapping(email string, start, end time.Time, includeMaybe bool) ([]Meeting, error)
+	FindMeetings(query MeetingQuery) ([]Meeting, int64, error)
+}
+
+/*
+	type MeetingQuery - describes which meetings a list request wants
+	@attributes:
+		Start, End - only meetings starting and ending between Start and End, if both are set
+		Recurring - with Start and End, only recurring meetings with occurrences that can fall between Start and End
+			instead of the single meetings between them
+		Email - only meetings which have a participant with Email, if set
+		IncludeCancelled - also match cancelled meetings
+		Skip - number of matching meetings to skip
+		Limit - max number of meetings to return, 0 for all
+		After - only meetings ordered after this cursor, if set. The total count ignores it
+*/
+
+type MeetingQuery struct {
+	Start            time.Time
+	End              time.Time
+	Recurring        bool
+	Email            string
+	IncludeCancelled bool
+	Skip             int64
+	Limit            int64
+	After            *MeetingCursor
+}
+
+/*
+	function MeetingQuery.matches()
+	@params:
+		meeting - meeting to test
+	@return:
+		bool - true if meeting matches the filters of the query
+*/
+
+func (query *MeetingQuery) matches(meeting Meeting) bool {
+	if !query.IncludeCancelled && meeting.Status == StatusCancelled {
+		return false
+	}
+	if !query.Start.IsZero() && !query.End.IsZero() {
+		if query.Recurring != (meeting.Recurrence != "") {
+			return false
+		}
+		if query.Recurring && !meeting.spans(query.Start, query.End) {
+			return false
+		}
+		if !query.Recurring && (meeting.StartTime.Before(query.Start) || meeting.EndTime.After(query.End)) {
+			return false
+		}
+	}
+	if query.Email != "" {
+		if _, ok := meeting.participant(query.Email); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// ErrWebhookNotFound is returned by a WebhookStore when no webhook matches the requested id
+var ErrWebhookNotFound = errors.New("Webhook not found")
+
+/*
+	interface WebhookStore - describes the storage of webhook subscriptions and their delivery log
+	@methods:
+		InsertWebhook - give the webhook a new ID and store it
+		GetWebhook - get a single webhook by id, ErrWebhookNotFound if it does not exist
+		DeleteWebhook - remove a webhook by id and its deliveries, ErrWebhookNotFound if it does not exist
+		FindWebhooks - get the webhooks subscribed to eventType, or all webhooks if eventType is empty, oldest first
+		InsertDelivery - give the delivery a new ID and append it to the log
+		FindDeliveries - get the latest deliveries of a webhook, newest first, at most limit
+		Delivered - true if the log has a successful delivery of the event to the webhook
+	@description:
+		MongoStore and MemoryStore implement it next to MeetingStore
+*/
+
+type WebhookStore interface {
+	InsertWebhook(webhook *Webhook) error
+	GetWebhook(id primitive.ObjectID) (Webhook, error)
+	DeleteWebhook(id primitive.ObjectID) error
+	FindWebhooks(eventType string) ([]Webhook, error)
+	InsertDelivery(delivery *Delivery) error
+	FindDeliveries(webhookID primitive.ObjectID, limit int64) ([]Delivery, error)
+	Delivered(webhookID, eventID primitive.ObjectID) (bool, error)
+}
+
+/*
+	interface MailStore - describes the log of the mails sent for the outbox events
+	@methods:
+		InsertSend - give the send a new ID and append it to the log
+		Sent - true if the log has a send of the event to the email, accepted or rejected for good
+	@description:
+		MongoStore and MemoryStore implement it next to OutboxStore
+*/
+
+type MailStore interface {
+	InsertSend(send *MailSend) error
+	Sent(eventID primitive.ObjectID, email string) (bool, error)
+}
+
+/*
+	interface OutboxStore - describes the outbox of the events recorded by InsertMeeting and UpdateMeeting
+	@methods:
+		ClaimEvents - get at most limit of the oldest events which are not dispatched, parked or claimed,
+			claim them for lease and count the attempt. A claimed event that is not acked can be claimed again
+			once the lease passed
+		AckEvent - mark an event as dispatched, it is never claimed again
+		FailEvent - record the reason a claimed event failed, park it if it was claimed maxAttempts times (never if
+			maxAttempts is 0), else hold it back for retryDelay(backoff, attempts) instead of the rest of its lease
+	@description:
+		MongoStore and MemoryStore implement it next to MeetingStore
+		Events are claimed until acked or parked, so every event is dispatched at least once, even if a dispatcher dies
+		Parked events stay in the outbox with their last error, they are never claimed again
+*/
+
+type OutboxStore interface {
+	ClaimEvents(limit int, lease time.Duration) ([]Event, error)
+	AckEvent(id primitive.ObjectID) error
+	FailEvent(id primitive.ObjectID, reason string, backoff time.Duration, maxAttempts int) error
+}
+
+/*
+	interface ReminderStore - describes the storage of the reminder schedule, kept in Meeting.NextReminder
+	@methods:
+		DueReminders - get at most limit meetings whose NextReminder is not after now, earliest first
+		FireReminder - if the stored meeting still has the NextReminder of meeting, set it to next (none if nil)
+			and add the events to the outbox in one atomic step and return true, otherwise change nothing and return false
+	@description:
+		MongoStore and MemoryStore implement it next to MeetingStore and OutboxStore
+		A meeting written or fired after it was read by DueReminders has another NextReminder, so FireReminder
+		records every reminder once, even when several schedulers read the same due meeting
+*/
+
+type ReminderStore interface {
+	DueReminders(now time.Time, limit int) ([]Meeting, error)
+	FireReminder(meeting *Meeting, next *time.Time, events []Event) (bool, error)
+}
+
+// ErrTokenExpired is returned by an EventFeed when it can not resume after the requested token
+var ErrTokenExpired = errors.New("Invalid or expired resume token")
+
+/*
+	type FeedEvent - an event of an EventFeed
+	@attributes:
+		Event - the event added to the outbox
+		Token - position of the event in the feed, resuming after it continues with the following events
+*/
+
+type FeedEvent struct {
+	Event Event
+	Token string
+}
+
+/*
+	interface EventFeed - describes a live feed of the events added to the outbox
+	@methods:
+		WatchEvents - get every event added to the outbox by any Api instance sharing the store until stop is closed,
+			from now on if after is empty, else from the event following the token after, ErrTokenExpired if the feed
+			no longer reaches back that far. The channel is closed once stop is closed or the feed fails.
+			The returned token is the current position of the feed
+	@description:
+		MongoStore and MemoryStore implement it next to OutboxStore
+		The feed is independent of the outbox dispatchers, every watcher sees every event
+*/
+
+type EventFeed interface {
+	WatchEvents(after string, stop <-chan struct{}) (<-chan FeedEvent, string, error)
+}
@@ -0,0 +1,758 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+/*
+	type MemoryStore - MeetingStore kept in process memory
+	@attributes:
+		mutex - guards all fields
+		meetings - meeting records in insertion order
+		outbox - events of the meeting writes which are not dispatched yet and the parked events, oldest first
+		watchers - channels of the event feeds
+		feed - the latest memoryFeedSize events with their tokens, oldest first, for feeds resuming after a token
+		sequence - number of recorded events, the token of an event is its number
+		webhooks - webhook subscriptions in insertion order
+		deliveries - webhook delivery log in insertion order
+		sends - mail send log in insertion order
+	@description:
+		Used by the tests and for embedding the Api without a mongoDb cluster
+*/
+
+type MemoryStore struct {
+	mutex    sync.RWMutex
+	meetings []Meeting
+	outbox   []outboxEntry
+	watchers []chan FeedEvent
+	feed     []FeedEvent
+	sequence int64
+
+	webhooks   []Webhook
+	deliveries []Delivery
+	sends      []MailSend
+}
+
+// memoryFeedSize is the number of latest events a MemoryStore keeps for resuming feeds
+const memoryFeedSize = 1000
+
+/*
+	type outboxEntry - an event in the outbox of a MemoryStore
+	@attributes:
+		event - the event
+		lockedUntil - the event can not be claimed again before this time
+		attempts - number of claims of the event
+		err - reason of the last failure
+		parked - true once the event failed too often, it is never claimed again
+*/
+
+type outboxEntry struct {
+	event       Event
+	lockedUntil time.Time
+	attempts    int
+	err         string
+	parked      bool
+}
+
+/*
+	function NewMemoryStore()
+	@return:
+		*MemoryStore - empty store
+*/
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+/*
+	function MemoryStore.InsertMeeting()
+	@params:
+		meeting - meeting to insert
+		event - event to record for the meeting
+	@description:
+		hold the write lock while checking every participant going for the meeting for overlaps,
+		then give the meeting a new ObjectID and append a copy of it to the store and the event to the outbox
+	@return:
+		primitive.ObjectID - inserted id
+		error - nil | *OverlapError
+*/
+
+func (store *MemoryStore) InsertMeeting(meeting *Meeting, event Event) (primitive.ObjectID, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if err := store.checkOverlaps(meeting); err != nil {
+		return primitive.NilObjectID, err
+	}
+
+	meeting.ID = primitive.NewObjectID()
+	store.meetings = append(store.meetings, copyMeeting(*meeting))
+	store.record(event.of(meeting))
+	return meeting.ID, nil
+}
+
+/*
+	function MemoryStore.UpdateMeeting()
+	@params:
+		meeting - meeting to replace, matched by Meeting.ID
+		event - event to record for the meeting
+	@description:
+		hold the write lock while checking that the stored record still has Meeting.Version and every participant going for
+		the meeting for overlaps with other meetings, then increment Meeting.Version, replace the stored record with a copy
+		of meeting and append the event to the outbox
+	@return:
+		error - nil | ErrMeetingNotFound | ErrMeetingChanged | *OverlapError
+*/
+
+func (store *MemoryStore) UpdateMeeting(meeting *Meeting, event Event) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	for i := range store.meetings {
+		if store.meetings[i].ID == meeting.ID {
+			if store.meetings[i].Version != meeting.Version {
+				return ErrMeetingChanged
+			}
+			if err := store.checkOverlaps(meeting); err != nil {
+				return err
+			}
+			meeting.Version++
+			store.record(event.replacing(store.meetings[i], meeting))
+			store.meetings[i] = copyMeeting(*meeting)
+			return nil
+		}
+	}
+	return ErrMeetingNotFound
+}
+
+/*
+	function MemoryStore.record()
+	@params:
+		event - event of a write
+	@description:
+		must be called holding the write lock
+		append the event to the outbox and the feed log and send it to the feeds,
+		a feed whose buffer is full is closed instead of missing the event, its watcher resumes after its last token
+*/
+
+func (store *MemoryStore) record(event Event) {
+	store.outbox = append(store.outbox, outboxEntry{event: event})
+
+	store.sequence++
+	recorded := FeedEvent{Event: event, Token: strconv.FormatInt(store.sequence, 10)}
+	store.feed = append(store.feed, recorded)
+	if len(store.feed) > memoryFeedSize {
+		store.feed = store.feed[len(store.feed)-memoryFeedSize:]
+	}
+	watchers := store.watchers[:0]
+	for _, watcher := range store.watchers {
+		select {
+		case watcher <- recorded:
+			watchers = append(watchers, watcher)
+		default:
+			close(watcher)
+		}
+	}
+	store.watchers = watchers
+}
+
+/*
+	function MemoryStore.checkOverlaps()
+	@params:
+		meeting - meeting being written
+	@description:
+		must be called holding the write lock
+		compares every occurrence of meeting with every occurrence of the other meetings of its participants
+	@return:
+		error - nil | *OverlapError if a participant going for meeting has another meeting at that time
+*/
+
+func (store *MemoryStore) checkOverlaps(meeting *Meeting) error {
+	from, to := meeting.checkWindow()
+	for _, email := range meeting.attendees() {
+		for _, other := range store.candidates(email, from, to, false) {
+			if other.ID != meeting.ID && meeting.conflictsWith(other) {
+				return &OverlapError{Email: email}
+			}
+		}
+	}
+	return nil
+}
+
+/*
+	function MemoryStore.DeleteMeeting()
+	@params:
+		id - meeting id
+		event - event to record for the removed meeting
+	@return:
+		error - nil | ErrMeetingNotFound
+*/
+
+func (store *MemoryStore) DeleteMeeting(id primitive.ObjectID, event Event) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	for i := range store.meetings {
+		if store.meetings[i].ID == id {
+			store.record(event.of(&store.meetings[i]))
+			store.meetings = append(store.meetings[:i], store.meetings[i+1:]...)
+			return nil
+		}
+	}
+	return ErrMeetingNotFound
+}
+
+/*
+	function MemoryStore.GetMeeting()
+	@params:
+		id - meeting id
+	@return:
+		Meeting - the meeting with id
+		error - nil | ErrMeetingNotFound
+*/
+
+func (store *MemoryStore) GetMeeting(id primitive.ObjectID) (Meeting, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	for _, meeting := range store.meetings {
+		if meeting.ID == id {
+			return copyMeeting(meeting), nil
+		}
+	}
+	return Meeting{}, ErrMeetingNotFound
+}
+
+/*
+	function MemoryStore.FindOverlapping()
+	@params:
+		email - participant email
+		start, end - time range to check
+		includeMaybe - also find the meetings the participant answered Maybe
+	@return:
+		[]Meeting - active meetings where the participant is going (rsvp Yes) with an occurrence starting before end and ending after start
+		error - nil
+*/
+
+func (store *MemoryStore) FindOverlapping(email string, start, end time.Time, includeMaybe bool) ([]Meeting, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	return store.overlapping(email, start, end, includeMaybe), nil
+}
+
+/*
+	function MemoryStore.overlapping()
+	@description:
+		FindOverlapping for callers already holding the lock
+*/
+
+func (store *MemoryStore) overlapping(email string, start, end time.Time, includeMaybe bool) []Meeting {
+	var meetings []Meeting
+	for _, meeting := range store.candidates(email, start, end, includeMaybe) {
+		if len(meeting.occurrences(start, end)) > 0 {
+			meetings = append(meetings, meeting)
+		}
+	}
+	return meetings
+}
+
+/*
+	function MemoryStore.candidates()
+	@params:
+		email - participant email
+		start, end - time range to check
+		includeMaybe - also match the meetings the participant answered Maybe
+	@description:
+		must be called holding the lock
+	@return:
+		[]Meeting - active meetings the participant is going for which, or any occurrence of which, can intersect start and end
+*/
+
+func (store *MemoryStore) candidates(email string, start, end time.Time, includeMaybe bool) []Meeting {
+	return store.match(func(meeting Meeting) bool {
+		participant, ok := meeting.participant(email)
+		going := participant.RSVP == "Yes" || (includeMaybe && participant.RSVP == "Maybe")
+		return ok && going && meeting.Status != StatusCancelled && meeting.spans(start, end)
+	})
+}
+
+/*
+	function MemoryStore.FindMeetings()
+	@params:
+		query - filters and page of the request
+	@description:
+		filter the meetings with MeetingQuery.matches(), sort them by start time and id and cut out the page
+		starting after the cursor if there is one
+	@return:
+		[]Meeting - meetings of the page
+		int64 - total number of matching meetings
+		error - nil
+*/
+
+func (store *MemoryStore) FindMeetings(query MeetingQuery) ([]Meeting, int64, error) {
+	meetings := store.filter(query.matches)
+	sortMeetings(meetings)
+
+	total := int64(len(meetings))
+	if query.After != nil {
+		for len(meetings) > 0 && !query.After.isBefore(meetings[0]) {
+			meetings = meetings[1:]
+		}
+	}
+
+	start, end := query.Skip, int64(len(meetings))
+	if start > end {
+		start = end
+	}
+	if query.Limit > 0 && start+query.Limit < end {
+		end = start + query.Limit
+	}
+	return append([]Meeting{}, meetings[start:end]...), total, nil
+}
+
+/*
+	function MemoryStore.filter()
+	@params:
+		match - predicate on a meeting
+	@return:
+		[]Meeting - copies of the meetings for which match is true
+*/
+
+func (store *MemoryStore) filter(match func(Meeting) bool) []Meeting {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	return store.match(match)
+}
+
+/*
+	function MemoryStore.match()
+	@description:
+		MemoryStore.filter() for callers already holding the lock
+*/
+
+func (store *MemoryStore) match(match func(Meeting) bool) []Meeting {
+	var meetings []Meeting
+	for _, meeting := range store.meetings {
+		if match(meeting) {
+			meetings = append(meetings, copyMeeting(meeting))
+		}
+	}
+	return meetings
+}
+
+/*
+	function copyMeeting()
+	@params:
+		meeting - meeting to copy
+	@description:
+		copy the slices so that callers can not modify stored records
+*/
+
+func copyMeeting(meeting Meeting) Meeting {
+	if meeting.Participants != nil {
+		meeting.Participants = append([]Participant(nil), meeting.Participants...)
+	}
+	if meeting.Exceptions != nil {
+		meeting.Exceptions = append([]time.Time(nil), meeting.Exceptions...)
+	}
+	if meeting.Overrides != nil {
+		meeting.Overrides = append([]Override(nil), meeting.Overrides...)
+	}
+	if meeting.Reminders != nil {
+		meeting.Reminders = append([]int(nil), meeting.Reminders...)
+	}
+	return meeting
+}
+
+/*
+	function MemoryStore.InsertWebhook()
+	@params:
+		webhook - webhook to insert
+	@return:
+		error - nil
+*/
+
+func (store *MemoryStore) InsertWebhook(webhook *Webhook) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	webhook.ID = primitive.NewObjectID()
+	copied := *webhook
+	copied.Events = append([]string{}, webhook.Events...)
+	store.webhooks = append(store.webhooks, copied)
+	return nil
+}
+
+/*
+	function MemoryStore.GetWebhook()
+	@params:
+		id - webhook id
+	@return:
+		Webhook - the webhook with id
+		error - nil | ErrWebhookNotFound
+*/
+
+func (store *MemoryStore) GetWebhook(id primitive.ObjectID) (Webhook, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	for _, webhook := range store.webhooks {
+		if webhook.ID == id {
+			return webhook, nil
+		}
+	}
+	return Webhook{}, ErrWebhookNotFound
+}
+
+/*
+	function MemoryStore.DeleteWebhook()
+	@params:
+		id - webhook id
+	@return:
+		error - nil | ErrWebhookNotFound
+*/
+
+func (store *MemoryStore) DeleteWebhook(id primitive.ObjectID) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	for i := range store.webhooks {
+		if store.webhooks[i].ID == id {
+			store.webhooks = append(store.webhooks[:i], store.webhooks[i+1:]...)
+
+			deliveries := store.deliveries[:0]
+			for _, delivery := range store.deliveries {
+				if delivery.WebhookID != id {
+					deliveries = append(deliveries, delivery)
+				}
+			}
+			store.deliveries = deliveries
+			return nil
+		}
+	}
+	return ErrWebhookNotFound
+}
+
+/*
+	function MemoryStore.FindWebhooks()
+	@params:
+		eventType - subscribed event type, empty for all webhooks
+	@return:
+		[]Webhook - matching webhooks, oldest first
+		error - nil
+*/
+
+func (store *MemoryStore) FindWebhooks(eventType string) ([]Webhook, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	webhooks := []Webhook{}
+	for _, webhook := range store.webhooks {
+		for _, subscribed := range webhook.Events {
+			if eventType == "" || subscribed == eventType {
+				webhooks = append(webhooks, webhook)
+				break
+			}
+		}
+	}
+	return webhooks, nil
+}
+
+/*
+	function MemoryStore.InsertDelivery()
+	@params:
+		delivery - delivery to log
+	@return:
+		error - nil
+*/
+
+func (store *MemoryStore) InsertDelivery(delivery *Delivery) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	delivery.ID = primitive.NewObjectID()
+	store.deliveries = append(store.deliveries, *delivery)
+	return nil
+}
+
+/*
+	function MemoryStore.FindDeliveries()
+	@params:
+		webhookID - webhook id
+		limit - max number of deliveries
+	@return:
+		[]Delivery - latest deliveries of the webhook, newest first
+		error - nil
+*/
+
+func (store *MemoryStore) FindDeliveries(webhookID primitive.ObjectID, limit int64) ([]Delivery, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	deliveries := []Delivery{}
+	for i := len(store.deliveries) - 1; i >= 0 && int64(len(deliveries)) < limit; i-- {
+		if store.deliveries[i].WebhookID == webhookID {
+			deliveries = append(deliveries, store.deliveries[i])
+		}
+	}
+	return deliveries, nil
+}
+
+/*
+	function MemoryStore.ClaimEvents()
+	@params:
+		limit - max number of events
+		lease - time the events stay claimed
+	@return:
+		[]Event - oldest events of the outbox which are neither parked nor claimed, now claimed until lease passed
+		error - nil
+*/
+
+func (store *MemoryStore) ClaimEvents(limit int, lease time.Duration) ([]Event, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	now := time.Now()
+	events := []Event{}
+	for i := range store.outbox {
+		if len(events) == limit {
+			break
+		}
+		if !store.outbox[i].parked && !store.outbox[i].lockedUntil.After(now) {
+			store.outbox[i].lockedUntil = now.Add(lease)
+			store.outbox[i].attempts++
+			events = append(events, store.outbox[i].event)
+		}
+	}
+	return events, nil
+}
+
+/*
+	function MemoryStore.AckEvent()
+	@params:
+		id - event id
+	@description:
+		remove the dispatched event from the outbox, unknown ids are ignored
+	@return:
+		error - nil
+*/
+
+func (store *MemoryStore) AckEvent(id primitive.ObjectID) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	for i := range store.outbox {
+		if store.outbox[i].event.ID == id {
+			store.outbox = append(store.outbox[:i], store.outbox[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+/*
+	function MemoryStore.FailEvent()
+	@params:
+		id - event id
+		reason - error of the attempt
+		backoff - wait after the first attempt
+		maxAttempts - number of attempts before the event is parked, 0 for no limit
+	@description:
+		park the event or hold it back until the backoff passed, unknown ids are ignored
+	@return:
+		error - nil
+*/
+
+func (store *MemoryStore) FailEvent(id primitive.ObjectID, reason string, backoff time.Duration, maxAttempts int) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	for i := range store.outbox {
+		if entry := &store.outbox[i]; entry.event.ID == id {
+			entry.err = reason
+			if maxAttempts > 0 && entry.attempts >= maxAttempts {
+				entry.parked = true
+			} else {
+				entry.lockedUntil = time.Now().Add(retryDelay(backoff, entry.attempts))
+			}
+			break
+		}
+	}
+	return nil
+}
+
+/*
+	function MemoryStore.Delivered()
+	@params:
+		webhookID - webhook id
+		eventID - event id
+	@return:
+		bool - true if the delivery log has a successful delivery of the event to the webhook
+		error - nil
+*/
+
+func (store *MemoryStore) Delivered(webhookID, eventID primitive.ObjectID) (bool, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	for _, delivery := range store.deliveries {
+		if delivery.WebhookID == webhookID && delivery.EventID == eventID && delivery.Success {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+/*
+	function MemoryStore.InsertSend()
+	@params:
+		send - send to log
+	@return:
+		error - nil
+*/
+
+func (store *MemoryStore) InsertSend(send *MailSend) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	send.ID = primitive.NewObjectID()
+	store.sends = append(store.sends, *send)
+	return nil
+}
+
+/*
+	function MemoryStore.Sent()
+	@params:
+		eventID - event id
+		email - recipient
+	@return:
+		bool - true if the send log has a send of the event to the email
+		error - nil
+*/
+
+func (store *MemoryStore) Sent(eventID primitive.ObjectID, email string) (bool, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	for _, send := range store.sends {
+		if send.EventID == eventID && send.Email == email {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+/*
+	function MemoryStore.DueReminders()
+	@params:
+		now - current time
+		limit - max number of meetings
+	@return:
+		[]Meeting - meetings with a NextReminder not after now, earliest first
+		error - nil
+*/
+
+func (store *MemoryStore) DueReminders(now time.Time, limit int) ([]Meeting, error) {
+	meetings := store.filter(func(meeting Meeting) bool {
+		return meeting.NextReminder != nil && !meeting.NextReminder.After(now)
+	})
+	sort.SliceStable(meetings, func(i, j int) bool {
+		return meetings[i].NextReminder.Before(*meetings[j].NextReminder)
+	})
+	if len(meetings) > limit {
+		meetings = meetings[:limit]
+	}
+	return meetings, nil
+}
+
+/*
+	function MemoryStore.FireReminder()
+	@params:
+		meeting - meeting read by DueReminders
+		next - following reminder, nil for none
+		events - reminder events to record
+	@description:
+		hold the write lock while comparing the stored NextReminder, moving it and appending the events to the outbox
+	@return:
+		bool - false if the meeting is gone or its NextReminder moved
+		error - nil
+*/
+
+func (store *MemoryStore) FireReminder(meeting *Meeting, next *time.Time, events []Event) (bool, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	for i := range store.meetings {
+		stored := &store.meetings[i]
+		if stored.ID != meeting.ID {
+			continue
+		}
+		if stored.NextReminder == nil || meeting.NextReminder == nil || !stored.NextReminder.Equal(*meeting.NextReminder) {
+			return false, nil
+		}
+		stored.NextReminder = next
+		for _, event := range events {
+			store.record(event)
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+/*
+	function MemoryStore.WatchEvents()
+	@params:
+		after - token to resume after, empty for none
+		stop - closing it ends the feed
+	@description:
+		holding the write lock, queue the logged events after the token and add the feed to the watchers,
+		so no event is missed or sent twice between both
+		the feed is closed when stop is closed or when it falls streamBuffer events behind
+	@return:
+		<-chan FeedEvent - events after the token or recorded from now on
+		string - token of the latest recorded event
+		error - nil | ErrTokenExpired if the token is not a number, in the future or older than the feed log
+*/
+
+func (store *MemoryStore) WatchEvents(after string, stop <-chan struct{}) (<-chan FeedEvent, string, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	var replay []FeedEvent
+	if after != "" {
+		sequence, err := strconv.ParseInt(after, 10, 64)
+		first := store.sequence - int64(len(store.feed))
+		if err != nil || sequence < first || sequence > store.sequence {
+			return nil, "", ErrTokenExpired
+		}
+		replay = store.feed[sequence-first:]
+	}
+
+	events := make(chan FeedEvent, streamBuffer+len(replay))
+	for _, event := range replay {
+		events <- event
+	}
+	store.watchers = append(store.watchers, events)
+
+	go func() {
+		<-stop
+		store.mutex.Lock()
+		defer store.mutex.Unlock()
+		// a lagging feed was already removed and closed by MemoryStore.record()
+		for i := range store.watchers {
+			if store.watchers[i] == events {
+				store.watchers = append(store.watchers[:i], store.watchers[i+1:]...)
+				close(events)
+				break
+			}
+		}
+	}()
+	return events, strconv.FormatInt(store.sequence, 10), nil
+}
@@ -0,0 +1,913 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+)
+
+/*
+	type MongoStore - MeetingStore backed by mongoDb
+	@attributes:
+		Db - MongoDb database instance
+		meetings - the meetings collection
+		reservations - one document per participant email, written by every transaction booking that participant
+		outbox - events of the meeting writes, written in the same transaction as the meeting
+		webhooks - the webhook subscriptions collection
+		deliveries - the webhook delivery log collection
+		sends - the mail send log collection
+		Timeout - timeout of each database operation, 10 seconds by default
+*/
+
+type MongoStore struct {
+	Db           *mongo.Database
+	meetings     *mongo.Collection
+	reservations *mongo.Collection
+	outbox       *mongo.Collection
+	webhooks     *mongo.Collection
+	deliveries   *mongo.Collection
+	sends        *mongo.Collection
+	Timeout      time.Duration
+}
+
+/*
+	function NewMongoStore()
+	@params:
+		db - mongoDb database instance
+	@return:
+		*MongoStore - store using the meetings, reservations, outbox, webhooks, webhook_deliveries and mail_sends
+			collections of db
+*/
+
+func NewMongoStore(db *mongo.Database) *MongoStore {
+	store := &MongoStore{
+		Db:           db,
+		meetings:     db.Collection("meetings"),
+		reservations: db.Collection("reservations"),
+		outbox:       db.Collection("outbox"),
+		webhooks:     db.Collection("webhooks"),
+		deliveries:   db.Collection("webhook_deliveries"),
+		sends:        db.Collection("mail_sends"),
+		Timeout:      10 * time.Second,
+	}
+	store.createIndexes()
+	return store
+}
+
+/*
+	function MongoStore.createIndexes()
+	@description:
+		create the indexes used for sorting, paging, the participant queries and the due reminders,
+		existing indexes are left as they are
+		dispatched outbox events and the mail sends expire after outboxRetention
+*/
+
+func (store *MongoStore) createIndexes() {
+	ctx := getContext(store.Timeout)
+
+	_, err := store.meetings.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "start_time", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "participants.email", Value: 1}, {Key: "start_time", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "next_reminder", Value: 1}}, Options: options.Index().SetSparse(true)},
+	})
+	if err != nil {
+		fmt.Println(err)
+	}
+
+	_, err = store.deliveries.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "webhook_id", Value: 1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "webhook_id", Value: 1}, {Key: "event_id", Value: 1}}},
+	})
+	if err != nil {
+		fmt.Println(err)
+	}
+
+	_, err = store.sends.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "event_id", Value: 1}, {Key: "email", Value: 1}}},
+		{Keys: bson.D{{Key: "created_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(int32(outboxRetention.Seconds()))},
+	})
+	if err != nil {
+		fmt.Println(err)
+	}
+
+	_, err = store.outbox.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "locked_until", Value: 1}}},
+		{Keys: bson.D{{Key: "dispatched_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(int32(outboxRetention.Seconds()))},
+	})
+	if err != nil {
+		fmt.Println(err)
+	}
+}
+
+/*
+	function MongoStore.InsertMeeting()
+	@params:
+		meeting - meeting to insert
+		event - event to record for the meeting
+	@description:
+		call MongoStore.book() with collection.InsertOne() and MongoStore.record() as the write
+	@return:
+		primitive.ObjectID - inserted id
+		error - nil | *OverlapError | error
+*/
+
+func (store *MongoStore) InsertMeeting(meeting *Meeting, event Event) (primitive.ObjectID, error) {
+	result, err := store.book(meeting, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		result, err := store.meetings.InsertOne(sessCtx, meeting)
+		if err != nil {
+			return nil, err
+		}
+		written := *meeting
+		written.ID = result.InsertedID.(primitive.ObjectID)
+		return result, store.record(sessCtx, event.of(&written))
+	})
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+
+	meeting.ID = result.(*mongo.InsertOneResult).InsertedID.(primitive.ObjectID)
+	return meeting.ID, nil
+}
+
+/*
+	function MongoStore.UpdateMeeting()
+	@params:
+		meeting - meeting to replace, matched by Meeting.ID
+		event - event to record for the meeting
+	@description:
+		call MongoStore.book() with collection.FindOneAndReplace(), which returns the replaced document,
+		and MongoStore.record() as the write
+		the replace matches the _id and the version the meeting was read with and writes the next version,
+		a retried transaction matches the same version again, so it fails with ErrMeetingChanged if another write committed
+	@return:
+		error - nil | ErrMeetingNotFound | ErrMeetingChanged | *OverlapError | error
+*/
+
+func (store *MongoStore) UpdateMeeting(meeting *Meeting, event Event) error {
+	written := *meeting
+	written.Version = meeting.Version + 1
+
+	// meetings stored before versions were added have no version field
+	var version interface{} = meeting.Version
+	if meeting.Version == 0 {
+		version = bson.M{"$in": bson.A{int64(0), nil}}
+	}
+
+	_, err := store.book(&written, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		var previous Meeting
+		err := store.meetings.FindOneAndReplace(sessCtx, bson.M{"_id": meeting.ID, "version": version}, &written).Decode(&previous)
+		if err == mongo.ErrNoDocuments {
+			count, err := store.meetings.CountDocuments(sessCtx, bson.M{"_id": meeting.ID})
+			if err != nil {
+				return nil, err
+			}
+			if count == 0 {
+				return nil, ErrMeetingNotFound
+			}
+			return nil, ErrMeetingChanged
+		}
+		if err != nil {
+			return nil, err
+		}
+		return nil, store.record(sessCtx, event.replacing(previous, &written))
+	})
+	if err == nil {
+		meeting.Version = written.Version
+	}
+	return err
+}
+
+/*
+	function MongoStore.record()
+	@params:
+		sessCtx - transaction of the meeting write
+		event - event of the write
+	@description:
+		insert the event into the outbox inside the transaction, so it is committed if and only if the meeting write is
+		the event id is the _id of the document, a retried transaction inserts a new event
+	@return:
+		error - nil | error
+*/
+
+func (store *MongoStore) record(sessCtx mongo.SessionContext, event Event) error {
+	_, err := store.outbox.InsertOne(sessCtx, bson.M{"_id": event.ID, "event": event, "locked_until": time.Time{}})
+	return err
+}
+
+// errReminderMoved aborts the transaction of MongoStore.FireReminder when the reminder was moved by someone else
+var errReminderMoved = errors.New("Reminder moved")
+
+/*
+	function MongoStore.book()
+	@params:
+		meeting - meeting being written
+		write - writes meeting inside the transaction
+	@description:
+		make sure a reservation document exists for every participant going for the meeting
+		then in one transaction:
+			bump the reservation document of each of those participants
+			check that no occurrence of meeting overlaps an occurrence of another meeting of those participants
+			call write
+		Two transactions booking the same participant both write its reservation document, so mongoDb aborts one of
+		them with a write conflict and WithTransaction retries it. The retry then sees the meeting committed by the other.
+		This holds across any number of Api instances. Transactions need a replica set or sharded cluster.
+	@return:
+		interface{} - result of write
+		error - nil | *OverlapError | error
+*/
+
+func (store *MongoStore) book(meeting *Meeting, write func(mongo.SessionContext) (interface{}, error)) (interface{}, error) {
+	ctx := getContext(store.Timeout)
+	attendees := meeting.attendees()
+	from, to := meeting.checkWindow()
+
+	// create missing reservation documents outside the transaction, concurrent upserts of a new _id would not be retried
+	for _, email := range attendees {
+		_, err := store.reservations.UpdateOne(ctx,
+			bson.M{"_id": email},
+			bson.M{"$setOnInsert": bson.M{"version": 0}},
+			options.Update().SetUpsert(true))
+		if err != nil && !mongo.IsDuplicateKeyError(err) {
+			return nil, err
+		}
+	}
+
+	return store.transaction(func(sessCtx mongo.SessionContext) (interface{}, error) {
+		for _, email := range attendees {
+			// reserve the participant, conflicting transactions abort here
+			_, err := store.reservations.UpdateOne(sessCtx, bson.M{"_id": email}, bson.M{"$inc": bson.M{"version": 1}})
+			if err != nil {
+				return nil, err
+			}
+
+			filter := overlapFilter(email, from, to, false)
+			if !meeting.ID.IsZero() {
+				// a meeting being edited does not overlap itself
+				filter["_id"] = bson.M{"$ne": meeting.ID}
+			}
+			var candidates []Meeting
+			cursor, err := store.meetings.Find(sessCtx, filter)
+			if err != nil {
+				return nil, err
+			}
+			if err = cursor.All(sessCtx, &candidates); err != nil {
+				return nil, err
+			}
+			for _, other := range candidates {
+				if meeting.conflictsWith(other) {
+					return nil, &OverlapError{Email: email}
+				}
+			}
+		}
+		return write(sessCtx)
+	})
+}
+
+/*
+	function MongoStore.transaction()
+	@params:
+		write - reads and writes of the transaction
+	@description:
+		run write in a snapshot transaction with majority write concern, WithTransaction retries it on write conflicts
+	@return:
+		interface{} - result of write
+		error - nil | error of write | error
+*/
+
+func (store *MongoStore) transaction(write func(mongo.SessionContext) (interface{}, error)) (interface{}, error) {
+	ctx := getContext(store.Timeout)
+
+	session, err := store.Db.Client().StartSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.EndSession(ctx)
+
+	txnOptions := options.Transaction().
+		SetReadConcern(readconcern.Snapshot()).
+		SetWriteConcern(writeconcern.Majority())
+
+	return session.WithTransaction(ctx, write, txnOptions)
+}
+
+/*
+	function MongoStore.DeleteMeeting()
+	@params:
+		id - meeting id
+		event - event to record for the removed meeting
+	@description:
+		in one transaction remove the meeting with collection.FindOneAndDelete() and record the event with the removed document
+	@return:
+		error - nil | ErrMeetingNotFound | error
+*/
+
+func (store *MongoStore) DeleteMeeting(id primitive.ObjectID, event Event) error {
+	_, err := store.transaction(func(sessCtx mongo.SessionContext) (interface{}, error) {
+		var deleted Meeting
+		err := store.meetings.FindOneAndDelete(sessCtx, bson.M{"_id": id}).Decode(&deleted)
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrMeetingNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		return nil, store.record(sessCtx, event.of(&deleted))
+	})
+	return err
+}
+
+/*
+	function MongoStore.GetMeeting()
+	@params:
+		id - meeting id
+	@return:
+		Meeting - the meeting with id
+		error - nil | ErrMeetingNotFound | error
+*/
+
+func (store *MongoStore) GetMeeting(id primitive.ObjectID) (Meeting, error) {
+	ctx := getContext(store.Timeout)
+
+	var meeting Meeting
+	err := store.meetings.FindOne(ctx, bson.M{"_id": id}).Decode(&meeting)
+	if err == mongo.ErrNoDocuments {
+		return meeting, ErrMeetingNotFound
+	}
+	return meeting, err
+}
+
+/*
+	function MongoStore.FindOverlapping()
+	@params:
+		email - participant email
+		start, end - time range to check
+		includeMaybe - also find the meetings the participant answered Maybe
+	@description:
+		find active meetings where the participant is going (rsvp Yes) which can intersect start and end
+		and keep those with an occurrence starting before end and ending after start
+	@return:
+		[]Meeting - overlapping meetings
+		error - nil | error
+*/
+
+func (store *MongoStore) FindOverlapping(email string, start, end time.Time, includeMaybe bool) ([]Meeting, error) {
+	candidates, err := store.find(overlapFilter(email, start, end, includeMaybe))
+	if err != nil {
+		return nil, err
+	}
+
+	var meetings []Meeting
+	for _, meeting := range candidates {
+		if len(meeting.occurrences(start, end)) > 0 {
+			meetings = append(meetings, meeting)
+		}
+	}
+	return meetings, nil
+}
+
+/*
+	function overlapFilter()
+	@params:
+		email - participant email
+		start, end - time range to check
+		includeMaybe - also match the meetings the participant answered Maybe
+	@return:
+		bson.M - filter for active meetings the participant is going for which intersect start and end
+			or recurring meetings which start before end and whose series does not end before start
+*/
+
+func overlapFilter(email string, start, end time.Time, includeMaybe bool) bson.M {
+	var rsvp interface{} = "Yes"
+	if includeMaybe {
+		rsvp = bson.M{"$in": bson.A{"Yes", "Maybe"}}
+	}
+	return bson.M{
+		// participant is going for meeting
+		"participants": bson.M{
+			"$elemMatch": bson.M{"email": email, "rsvp": rsvp},
+		},
+		"start_time": bson.M{"$lt": end},
+		"$or": spanFilter(start),
+		// cancelled meetings do not block anyone
+		"status": bson.M{"$ne": StatusCancelled},
+	}
+}
+
+/*
+	function spanFilter()
+	@params:
+		start - start of a time range
+	@return:
+		bson.A - $or conditions for meetings ending after start: single meetings by end_time
+			and recurring meetings by series_end, which is missing for series without end
+*/
+
+func spanFilter(start time.Time) bson.A {
+	return bson.A{
+		bson.M{"recurrence": bson.M{"$exists": false}, "end_time": bson.M{"$gt": start}},
+		bson.M{"recurrence": bson.M{"$exists": true}, "series_end": bson.M{"$exists": false}},
+		bson.M{"series_end": bson.M{"$gt": start}},
+	}
+}
+
+/*
+	function MongoStore.FindMeetings()
+	@params:
+		query - filters and page of the request
+	@description:
+		build the bson filter for the query
+		count all matching documents, then find the page sorted by start_time and _id with skip and limit
+		a cursor adds the condition (start_time, _id) > (cursor.StartTime, cursor.ID) to the find
+	@return:
+		[]Meeting - meetings of the page
+		int64 - total number of matching meetings
+		error - nil | error
+*/
+
+func (store *MongoStore) FindMeetings(query MeetingQuery) ([]Meeting, int64, error) {
+	ctx := getContext(store.Timeout)
+
+	filter := bson.M{}
+	if !query.Start.IsZero() && !query.End.IsZero() && query.Recurring {
+		filter["recurrence"] = bson.M{"$exists": true}
+		filter["start_time"] = bson.M{"$lt": query.End}
+		filter["$or"] = spanFilter(query.Start)
+	} else if !query.Start.IsZero() && !query.End.IsZero() {
+		filter["recurrence"] = bson.M{"$exists": false}
+		filter["start_time"] = bson.M{"$gte": query.Start}
+		filter["end_time"] = bson.M{"$lte": query.End}
+	}
+	if query.Email != "" {
+		filter["participants.email"] = query.Email
+	}
+	if !query.IncludeCancelled {
+		filter["status"] = bson.M{"$ne": StatusCancelled}
+	}
+
+	total, err := store.meetings.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if query.After != nil {
+		filter = bson.M{"$and": bson.A{filter, bson.M{"$or": bson.A{
+			bson.M{"start_time": bson.M{"$gt": query.After.StartTime}},
+			bson.M{"start_time": query.After.StartTime, "_id": bson.M{"$gt": query.After.ID}},
+		}}}}
+	}
+
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "start_time", Value: 1}, {Key: "_id", Value: 1}}).
+		SetSkip(query.Skip)
+	if query.Limit > 0 {
+		findOptions.SetLimit(query.Limit)
+	}
+
+	meetings, err := store.find(filter, findOptions)
+	return meetings, total, err
+}
+
+/*
+	function MongoStore.find()
+	@params:
+		filter - bson query filter
+		findOptions - sort, skip and limit options
+	@description:
+		query the collection and call cursor to populate all objects found
+	@return:
+		[]Meeting - matching meetings
+		error - nil | error
+*/
+
+func (store *MongoStore) find(filter interface{}, findOptions ...*options.FindOptions) ([]Meeting, error) {
+	ctx := getContext(store.Timeout)
+
+	meetings := []Meeting{}
+	cursor, err := store.meetings.Find(ctx, filter, findOptions...)
+	if err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+	if err = cursor.All(ctx, &meetings); err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+	return meetings, nil
+}
+
+/*
+	function MongoStore.InsertWebhook()
+	@params:
+		webhook - webhook to insert
+	@return:
+		error - nil | error
+*/
+
+func (store *MongoStore) InsertWebhook(webhook *Webhook) error {
+	ctx := getContext(store.Timeout)
+
+	webhook.ID = primitive.NewObjectID()
+	_, err := store.webhooks.InsertOne(ctx, webhook)
+	return err
+}
+
+/*
+	function MongoStore.GetWebhook()
+	@params:
+		id - webhook id
+	@return:
+		Webhook - the webhook with id
+		error - nil | ErrWebhookNotFound | error
+*/
+
+func (store *MongoStore) GetWebhook(id primitive.ObjectID) (Webhook, error) {
+	ctx := getContext(store.Timeout)
+
+	var webhook Webhook
+	err := store.webhooks.FindOne(ctx, bson.M{"_id": id}).Decode(&webhook)
+	if err == mongo.ErrNoDocuments {
+		return webhook, ErrWebhookNotFound
+	}
+	return webhook, err
+}
+
+/*
+	function MongoStore.DeleteWebhook()
+	@params:
+		id - webhook id
+	@description:
+		remove the webhook, then its delivery log
+	@return:
+		error - nil | ErrWebhookNotFound | error
+*/
+
+func (store *MongoStore) DeleteWebhook(id primitive.ObjectID) error {
+	ctx := getContext(store.Timeout)
+
+	result, err := store.webhooks.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrWebhookNotFound
+	}
+	_, err = store.deliveries.DeleteMany(ctx, bson.M{"webhook_id": id})
+	return err
+}
+
+/*
+	function MongoStore.FindWebhooks()
+	@params:
+		eventType - subscribed event type, empty for all webhooks
+	@return:
+		[]Webhook - matching webhooks, oldest first
+		error - nil | error
+*/
+
+func (store *MongoStore) FindWebhooks(eventType string) ([]Webhook, error) {
+	ctx := getContext(store.Timeout)
+
+	filter := bson.M{}
+	if eventType != "" {
+		filter["events"] = eventType
+	}
+	cursor, err := store.webhooks.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	webhooks := []Webhook{}
+	err = cursor.All(ctx, &webhooks)
+	return webhooks, err
+}
+
+/*
+	function MongoStore.InsertDelivery()
+	@params:
+		delivery - delivery to log
+	@return:
+		error - nil | error
+*/
+
+func (store *MongoStore) InsertDelivery(delivery *Delivery) error {
+	ctx := getContext(store.Timeout)
+
+	delivery.ID = primitive.NewObjectID()
+	_, err := store.deliveries.InsertOne(ctx, delivery)
+	return err
+}
+
+/*
+	function MongoStore.FindDeliveries()
+	@params:
+		webhookID - webhook id
+		limit - max number of deliveries
+	@return:
+		[]Delivery - latest deliveries of the webhook, newest first
+		error - nil | error
+*/
+
+func (store *MongoStore) FindDeliveries(webhookID primitive.ObjectID, limit int64) ([]Delivery, error) {
+	ctx := getContext(store.Timeout)
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}).SetLimit(limit)
+	cursor, err := store.deliveries.Find(ctx, bson.M{"webhook_id": webhookID}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	deliveries := []Delivery{}
+	err = cursor.All(ctx, &deliveries)
+	return deliveries, err
+}
+
+/*
+	function MongoStore.ClaimEvents()
+	@params:
+		limit - max number of events
+		lease - time the events stay claimed
+	@description:
+		claim the oldest outbox events which are neither dispatched, parked nor claimed one by one with findOneAndUpdate,
+		so dispatchers of several Api instances never claim the same event at the same time, and count the attempt
+	@return:
+		[]Event - claimed events, oldest first
+		error - nil | error
+*/
+
+func (store *MongoStore) ClaimEvents(limit int, lease time.Duration) ([]Event, error) {
+	ctx := getContext(store.Timeout)
+
+	events := []Event{}
+	for len(events) < limit {
+		now := time.Now()
+		var entry struct {
+			Event Event `bson:"event"`
+		}
+		err := store.outbox.FindOneAndUpdate(ctx,
+			bson.M{"dispatched_at": bson.M{"$exists": false}, "parked_at": bson.M{"$exists": false}, "locked_until": bson.M{"$lte": now}},
+			bson.M{"$set": bson.M{"locked_until": now.Add(lease)}, "$inc": bson.M{"attempts": 1}},
+			options.FindOneAndUpdate().SetSort(bson.D{{Key: "_id", Value: 1}})).Decode(&entry)
+		if err == mongo.ErrNoDocuments {
+			break
+		}
+		if err != nil {
+			return events, err
+		}
+		events = append(events, entry.Event)
+	}
+	return events, nil
+}
+
+/*
+	function MongoStore.AckEvent()
+	@params:
+		id - event id
+	@description:
+		mark the event as dispatched, the ttl index removes it after outboxRetention
+	@return:
+		error - nil | error
+*/
+
+func (store *MongoStore) AckEvent(id primitive.ObjectID) error {
+	ctx := getContext(store.Timeout)
+
+	_, err := store.outbox.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"dispatched_at": time.Now()}})
+	return err
+}
+
+/*
+	function MongoStore.FailEvent()
+	@params:
+		id - event id
+		reason - error of the attempt
+		backoff - wait after the first attempt
+		maxAttempts - number of attempts before the event is parked, 0 for no limit
+	@description:
+		read the attempts of the event and set its error together with parked_at or the locked_until after the backoff,
+		the event is still claimed by the caller, so no other dispatcher writes it in between
+	@return:
+		error - nil | error
+*/
+
+func (store *MongoStore) FailEvent(id primitive.ObjectID, reason string, backoff time.Duration, maxAttempts int) error {
+	ctx := getContext(store.Timeout)
+
+	var entry struct {
+		Attempts int `bson:"attempts"`
+	}
+	err := store.outbox.FindOne(ctx, bson.M{"_id": id}).Decode(&entry)
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	set := bson.M{"error": reason}
+	if maxAttempts > 0 && entry.Attempts >= maxAttempts {
+		set["parked_at"] = time.Now()
+	} else {
+		set["locked_until"] = time.Now().Add(retryDelay(backoff, entry.Attempts))
+	}
+	_, err = store.outbox.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": set})
+	return err
+}
+
+/*
+	function MongoStore.Delivered()
+	@params:
+		webhookID - webhook id
+		eventID - event id
+	@return:
+		bool - true if the delivery log has a successful delivery of the event to the webhook
+		error - nil | error
+*/
+
+func (store *MongoStore) Delivered(webhookID, eventID primitive.ObjectID) (bool, error) {
+	ctx := getContext(store.Timeout)
+
+	count, err := store.deliveries.CountDocuments(ctx,
+		bson.M{"webhook_id": webhookID, "event_id": eventID, "success": true},
+		options.Count().SetLimit(1))
+	return count > 0, err
+}
+
+/*
+	function MongoStore.InsertSend()
+	@params:
+		send - send to log
+	@return:
+		error - nil | error
+*/
+
+func (store *MongoStore) InsertSend(send *MailSend) error {
+	ctx := getContext(store.Timeout)
+
+	send.ID = primitive.NewObjectID()
+	_, err := store.sends.InsertOne(ctx, send)
+	return err
+}
+
+/*
+	function MongoStore.Sent()
+	@params:
+		eventID - event id
+		email - recipient
+	@return:
+		bool - true if the send log has a send of the event to the email
+		error - nil | error
+*/
+
+func (store *MongoStore) Sent(eventID primitive.ObjectID, email string) (bool, error) {
+	ctx := getContext(store.Timeout)
+
+	count, err := store.sends.CountDocuments(ctx, bson.M{"event_id": eventID, "email": email}, options.Count().SetLimit(1))
+	return count > 0, err
+}
+
+/*
+	function MongoStore.DueReminders()
+	@params:
+		now - current time
+		limit - max number of meetings
+	@return:
+		[]Meeting - meetings with a next_reminder not after now, earliest first
+		error - nil | error
+*/
+
+func (store *MongoStore) DueReminders(now time.Time, limit int) ([]Meeting, error) {
+	findOptions := options.Find().SetSort(bson.D{{Key: "next_reminder", Value: 1}}).SetLimit(int64(limit))
+	return store.find(bson.M{"next_reminder": bson.M{"$lte": now}}, findOptions)
+}
+
+/*
+	function MongoStore.FireReminder()
+	@params:
+		meeting - meeting read by DueReminders
+		next - following reminder, nil for none
+		events - reminder events to record
+	@description:
+		in one transaction move next_reminder of the meeting, matched by _id and the next_reminder it was read with,
+		and insert the events into the outbox. Schedulers firing the same meeting both write its document,
+		so one of them is aborted and its retry no longer matches
+	@return:
+		bool - false if the meeting is gone or its next_reminder moved
+		error - nil | error
+*/
+
+func (store *MongoStore) FireReminder(meeting *Meeting, next *time.Time, events []Event) (bool, error) {
+	update := bson.M{"$unset": bson.M{"next_reminder": ""}}
+	if next != nil {
+		update = bson.M{"$set": bson.M{"next_reminder": *next}}
+	}
+
+	_, err := store.transaction(func(sessCtx mongo.SessionContext) (interface{}, error) {
+		result, err := store.meetings.UpdateOne(sessCtx, bson.M{"_id": meeting.ID, "next_reminder": meeting.NextReminder}, update)
+		if err != nil {
+			return nil, err
+		}
+		if result.MatchedCount == 0 {
+			return nil, errReminderMoved
+		}
+		for _, event := range events {
+			if err := store.record(sessCtx, event); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	})
+	if err == errReminderMoved {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+/*
+	function MongoStore.WatchEvents()
+	@params:
+		after - token to resume after, empty for none
+		stop - closing it ends the feed
+	@description:
+		open a change stream on the inserts of the outbox collection, so the feed sees the events committed by
+		every Api instance. The tokens are the resume tokens of the change stream, which reach back as far as the oplog.
+		Change streams need a replica set or sharded cluster like the transactions
+	@return:
+		<-chan FeedEvent - events after the token or inserted from now on
+		string - resume token of the opened stream
+		error - nil | ErrTokenExpired | error if the change stream could not be opened
+*/
+
+func (store *MongoStore) WatchEvents(after string, stop <-chan struct{}) (<-chan FeedEvent, string, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: bson.M{"operationType": "insert"}}}}
+	streamOptions := options.ChangeStream()
+	if after != "" {
+		streamOptions.SetResumeAfter(bson.M{"_data": after})
+	}
+	stream, err := store.outbox.Watch(ctx, pipeline, streamOptions)
+	if err != nil {
+		cancel()
+		if serverErr, ok := err.(mongo.ServerError); ok && after != "" && (serverErr.HasErrorCode(280) || serverErr.HasErrorCode(286)) {
+			return nil, "", ErrTokenExpired
+		}
+		return nil, "", err
+	}
+
+	go func() {
+		select {
+		case <-stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	events := make(chan FeedEvent)
+	go func() {
+		defer close(events)
+		defer cancel()
+		defer stream.Close(context.Background())
+
+		for stream.Next(ctx) {
+			var change struct {
+				Document struct {
+					Event Event `bson:"event"`
+				} `bson:"fullDocument"`
+			}
+			if err := stream.Decode(&change); err != nil {
+				fmt.Println(err)
+				continue
+			}
+			select {
+			case events <- FeedEvent{Event: change.Document.Event, Token: resumeToken(stream)}:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if err := stream.Err(); err != nil && ctx.Err() == nil {
+			fmt.Println(err)
+		}
+	}()
+	return events, resumeToken(stream), nil
+}
+
+/*
+	function resumeToken()
+	@params:
+		stream - change stream
+	@return:
+		string - the _data of the current resume token of stream, empty if it has none yet
+*/
+
+func resumeToken(stream *mongo.ChangeStream) string {
+	data, ok := stream.ResumeToken().Lookup("_data").StringValueOK()
+	if !ok {
+		return ""
+	}
+	return data
+}
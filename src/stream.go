@@ -0,0 +1,230 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// streamBuffer is the number of events a stream subscriber may fall behind before it is dropped
+const streamBuffer = 64
+
+// streamHeartbeat is the wait between two comments keeping an idle stream open through proxies
+const streamHeartbeat = 15 * time.Second
+
+// streamedTypes are the event types sent to stream and live calendar subscribers
+var streamedTypes = []string{EventMeetingCreated, EventMeetingUpdated, EventMeetingCancelled, EventMeetingDeleted, EventRSVPChanged}
+
+/*
+	type EventHub - shares one feed of the store among the stream subscribers
+	@attributes:
+		Feed - live feed of the events of the store
+		mutex - guards the fields below
+		subscribers - channel of every subscriber
+		stop - closing it stops the running feed, nil if no feed runs
+	@description:
+		The feed is started by the first subscriber and stopped when the last one leaves, so an Api without
+		stream clients does not watch the store
+*/
+
+type EventHub struct {
+	Feed EventFeed
+
+	mutex       sync.Mutex
+	subscribers map[chan Event]bool
+	stop        chan struct{}
+}
+
+/*
+	function NewEventHub()
+	@params:
+		feed - live feed of the events of the store
+	@return:
+		*EventHub - hub without subscribers
+*/
+
+func NewEventHub(feed EventFeed) *EventHub {
+	return &EventHub{Feed: feed, subscribers: map[chan Event]bool{}}
+}
+
+/*
+	function EventHub.Subscribe()
+	@description:
+		start the feed if it is not running and add a subscriber
+		the channel of the subscriber is closed if it falls streamBuffer events behind or the feed fails,
+		the client then has to subscribe again
+	@return:
+		<-chan Event - events of the feed from now on
+		func() - removes the subscriber, must be called once it leaves
+		error - nil | error if the feed could not be started
+*/
+
+func (hub *EventHub) Subscribe() (<-chan Event, func(), error) {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	if hub.stop == nil {
+		stop := make(chan struct{})
+		events, _, err := hub.Feed.WatchEvents("", stop)
+		if err != nil {
+			return nil, nil, err
+		}
+		hub.stop = stop
+		go hub.broadcast(events, stop)
+	}
+
+	subscriber := make(chan Event, streamBuffer)
+	hub.subscribers[subscriber] = true
+	return subscriber, func() { hub.unsubscribe(subscriber) }, nil
+}
+
+/*
+	function EventHub.unsubscribe()
+	@params:
+		subscriber - channel of the leaving subscriber
+	@description:
+		remove the subscriber and stop the feed if it was the last one
+*/
+
+func (hub *EventHub) unsubscribe(subscriber chan Event) {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	if hub.subscribers[subscriber] {
+		delete(hub.subscribers, subscriber)
+		close(subscriber)
+	}
+	if len(hub.subscribers) == 0 && hub.stop != nil {
+		close(hub.stop)
+		hub.stop = nil
+	}
+}
+
+/*
+	function EventHub.watching()
+	@return:
+		bool - true if the feed runs
+*/
+
+func (hub *EventHub) watching() bool {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+	return hub.stop != nil
+}
+
+/*
+	function EventHub.broadcast()
+	@params:
+		events - the running feed
+		stop - stop channel of the feed
+	@description:
+		send every event of the feed to every subscriber without blocking, subscribers with a full buffer are dropped
+		if the feed closes while it is still the running one, it failed and all subscribers are dropped
+*/
+
+func (hub *EventHub) broadcast(events <-chan FeedEvent, stop chan struct{}) {
+	for event := range events {
+		hub.mutex.Lock()
+		for subscriber := range hub.subscribers {
+			select {
+			case subscriber <- event.Event:
+			default:
+				delete(hub.subscribers, subscriber)
+				close(subscriber)
+			}
+		}
+		hub.mutex.Unlock()
+	}
+
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+	if hub.stop == stop {
+		for subscriber := range hub.subscribers {
+			delete(hub.subscribers, subscriber)
+			close(subscriber)
+		}
+		close(hub.stop)
+		hub.stop = nil
+	}
+}
+
+/*
+	function streamedType()
+	@return:
+		bool - true if name is one of streamedTypes
+*/
+
+func streamedType(name string) bool {
+	for _, eventType := range streamedTypes {
+		if eventType == name {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+	type StreamFilter - selects the events of a stream, like the query modes of the meeting listing
+	@attributes:
+		Email - only meetings which have a participant with Email, if set
+		Start, End - only meetings with an occurrence starting and ending between Start and End, if both are set
+*/
+
+type StreamFilter struct {
+	Email string
+	Start time.Time
+	End   time.Time
+}
+
+/*
+	function StreamFilter.matches()
+	@params:
+		event - event of the feed
+	@description:
+		cancelled meetings still match, so subscribers learn about the cancellation
+	@return:
+		bool - true if the event is one of streamedTypes and its meeting matches the filter
+*/
+
+func (filter *StreamFilter) matches(event Event) bool {
+	if !streamedType(event.Type) {
+		return false
+	}
+
+	if filter.Email != "" {
+		if _, ok := event.Meeting.participant(filter.Email); !ok {
+			return false
+		}
+	}
+	if !filter.Start.IsZero() && !filter.End.IsZero() {
+		for _, occurrence := range event.Meeting.occurrences(filter.Start, filter.End) {
+			if !occurrence.StartTime.Before(filter.Start) && !occurrence.EndTime.After(filter.End) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+/*
+	function writeSSE()
+	@params:
+		w - response of the stream
+		event - event to send
+	@description:
+		write the event as a server-sent event with its id, its type as event name and its json as data
+	@return:
+		error - nil | write error
+*/
+
+func writeSSE(w io.Writer, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID.Hex(), event.Type, data)
+	return err
+}
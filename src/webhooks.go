@@ -0,0 +1,351 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// EventWebhookTest is the type of the event sent by the webhook test endpoint
+const EventWebhookTest = "webhook.test"
+
+// sharedAddressSpace is the carrier-grade NAT range of RFC 6598, not reachable from the internet either
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+/*
+	type Webhook - a client subscription to meeting events
+	@attributes:
+		ID - id of the webhook
+		URL - http or https url the events are posted to
+		Secret - key of the HMAC signature of each delivery
+		Events - subscribed event types
+		CreatedAt - time of the subscription
+*/
+
+type Webhook struct {
+	ID        primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	URL       string             `json:"url" bson:"url"`
+	Secret    string             `json:"secret,omitempty" bson:"secret"`
+	Events    []string           `json:"events" bson:"events"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+}
+
+/*
+	type Delivery - one attempt to post an event to a webhook
+	@attributes:
+		ID - id of the delivery
+		WebhookID - webhook the event was posted to
+		EventID, EventType - the posted event
+		Attempt - number of the attempt, starting at 1
+		StatusCode - http status of the response, 0 if there was none
+		Error - reason the attempt failed, empty on success
+		Success - true if the webhook answered with a 2xx status
+		CreatedAt - time of the attempt
+*/
+
+type Delivery struct {
+	ID         primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	WebhookID  primitive.ObjectID `json:"webhook_id" bson:"webhook_id"`
+	EventID    primitive.ObjectID `json:"event_id" bson:"event_id"`
+	EventType  string             `json:"event_type" bson:"event_type"`
+	Attempt    int                `json:"attempt" bson:"attempt"`
+	StatusCode int                `json:"status_code" bson:"status_code"`
+	Error      string             `json:"error,omitempty" bson:"error,omitempty"`
+	Success    bool               `json:"success" bson:"success"`
+	CreatedAt  time.Time          `json:"created_at" bson:"created_at"`
+}
+
+/*
+	function Webhook.validate()
+	@params:
+		allowPrivate - also accept urls of loopback, private and link-local addresses
+	@description:
+		perform checks:
+			the url is an absolute http or https url
+			unless allowPrivate, every address the host resolves to is public, so that the api can not be
+			used to reach the network it runs in
+			every event type is known, no event types subscribes to all of them
+		generate a secret if none is given
+	@return:
+		error - nil | error
+*/
+
+func (webhook *Webhook) validate(allowPrivate bool) error {
+	target, err := url.Parse(webhook.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return errors.New("Invalid webhook url")
+	}
+	if !allowPrivate {
+		ips, err := net.LookupIP(target.Hostname())
+		if err != nil {
+			return errors.New("Webhook host " + target.Hostname() + " does not resolve")
+		}
+		for _, ip := range ips {
+			if !publicIP(ip) {
+				return errors.New("Webhook url must not point to a private address")
+			}
+		}
+	}
+
+	if len(webhook.Events) == 0 {
+		webhook.Events = append([]string{}, eventTypes...)
+	}
+	for _, eventType := range webhook.Events {
+		if !knownEventType(eventType) {
+			return errors.New("Unknown event type " + eventType)
+		}
+	}
+
+	if webhook.Secret == "" {
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return err
+		}
+		webhook.Secret = hex.EncodeToString(key)
+	}
+	return nil
+}
+
+/*
+	function publicIP()
+	@params:
+		ip - address of a webhook host
+	@return:
+		bool - false for loopback, private, link-local, multicast, unspecified and carrier-grade NAT addresses
+*/
+
+func publicIP(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() && !ip.IsMulticast() && !ip.IsUnspecified() && !sharedAddressSpace.Contains(ip)
+}
+
+/*
+	function publicDial()
+	@params:
+		network - network of the connection
+		address - ip:port the dialer is about to connect to, after the name was resolved
+		conn - the socket, unused
+	@description:
+		net.Dialer Control of the webhook client. It sees the address actually connected to, so a host that resolved
+		to a public address when the webhook was registered can not be pointed at a private one later
+	@return:
+		error - nil | error if the address is not public, the connection is not made
+*/
+
+func publicDial(network, address string, conn syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !publicIP(ip) {
+		return errors.New("Webhook address " + host + " is not public")
+	}
+	return nil
+}
+
+/*
+	type WebhookDispatcher - posts events to the subscribed webhooks
+	@attributes:
+		Store - storage of the webhooks and the delivery log
+		Client - http client used for the posts
+		MaxAttempts - number of attempts per event and webhook before giving up
+		Backoff - wait before the second attempt, doubled for every further attempt
+		AllowPrivate - webhooks may be registered for loopback, private and link-local addresses
+*/
+
+type WebhookDispatcher struct {
+	Store        WebhookStore
+	Client       *http.Client
+	MaxAttempts  int
+	Backoff      time.Duration
+	AllowPrivate bool
+}
+
+/*
+	function NewWebhookDispatcher()
+	@params:
+		store - storage of the webhooks and the delivery log
+		allowPrivate - allow webhooks of loopback, private and link-local addresses, for development
+	@description:
+		unless allowPrivate, the client only connects to public addresses, see publicDial(), and ignores proxies
+		so that the check applies to the webhook itself
+	@return:
+		*WebhookDispatcher - dispatcher with a 10 second timeout, 5 attempts and a 1 second initial backoff
+*/
+
+func NewWebhookDispatcher(store WebhookStore, allowPrivate bool) *WebhookDispatcher {
+	client := &http.Client{Timeout: 10 * time.Second}
+	if !allowPrivate {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.Proxy = nil
+		transport.DialContext = (&net.Dialer{Timeout: 10 * time.Second, Control: publicDial}).DialContext
+		client.Transport = transport
+	}
+	return &WebhookDispatcher{
+		Store:        store,
+		Client:       client,
+		MaxAttempts:  5,
+		Backoff:      time.Second,
+		AllowPrivate: allowPrivate,
+	}
+}
+
+/*
+	function WebhookDispatcher.Publish()
+	@params:
+		event - event to post
+	@description:
+		find the webhooks subscribed to the event type and deliver the event to all of them in parallel
+		returns once every delivery succeeded or gave up, so the outbox acks the event only after that
+	@return:
+		error - nil | error if the webhooks could not be loaded
+*/
+
+func (dispatcher *WebhookDispatcher) Publish(event Event) error {
+	webhooks, err := dispatcher.Store.FindWebhooks(event.Type)
+	if err != nil {
+		return err
+	}
+
+	var pending sync.WaitGroup
+	for _, webhook := range webhooks {
+		pending.Add(1)
+		go func(webhook Webhook) {
+			defer pending.Done()
+			dispatcher.deliver(webhook, event)
+		}(webhook)
+	}
+	pending.Wait()
+	return nil
+}
+
+/*
+	function WebhookDispatcher.deliver()
+	@params:
+		webhook - subscribed webhook
+		event - event to post
+	@description:
+		skip webhooks which already got the event, an event claimed again from the outbox is not posted twice
+		post the event until the webhook answers with a 2xx status or MaxAttempts are used,
+		waiting Backoff, 2*Backoff, 4*Backoff ... between the attempts
+		every attempt is written to the delivery log
+*/
+
+func (dispatcher *WebhookDispatcher) deliver(webhook Webhook, event Event) {
+	if delivered, err := dispatcher.Store.Delivered(webhook.ID, event.ID); err != nil || delivered {
+		return
+	}
+
+	wait := dispatcher.Backoff
+	for attempt := 1; attempt <= dispatcher.MaxAttempts; attempt++ {
+		if dispatcher.send(webhook, event, attempt).Success {
+			return
+		}
+		if attempt < dispatcher.MaxAttempts {
+			time.Sleep(wait)
+			wait *= 2
+		}
+	}
+}
+
+/*
+	function WebhookDispatcher.send()
+	@params:
+		webhook - subscribed webhook
+		event - event to post
+		attempt - number of the attempt
+	@description:
+		post the event as json with the headers:
+			X-Webhook-Id - event id, the same for every attempt
+			X-Webhook-Event - event type
+			X-Webhook-Timestamp - unix time of the attempt
+			X-Webhook-Signature - sha256= and the hex HMAC-SHA256 of timestamp + "." + body with the webhook secret
+		and write the attempt to the delivery log
+	@return:
+		Delivery - the logged attempt
+*/
+
+func (dispatcher *WebhookDispatcher) send(webhook Webhook, event Event, attempt int) Delivery {
+	delivery := Delivery{
+		WebhookID: webhook.ID,
+		EventID:   event.ID,
+		EventType: event.Type,
+		Attempt:   attempt,
+		CreatedAt: time.Now(),
+	}
+
+	body, _ := json.Marshal(event)
+	timestamp := strconv.FormatInt(delivery.CreatedAt.Unix(), 10)
+	request, err := http.NewRequest("POST", webhook.URL, bytes.NewReader(body))
+	if err == nil {
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("X-Webhook-Id", event.ID.Hex())
+		request.Header.Set("X-Webhook-Event", event.Type)
+		request.Header.Set("X-Webhook-Timestamp", timestamp)
+		request.Header.Set("X-Webhook-Signature", "sha256="+signWebhook(webhook.Secret, timestamp, body))
+
+		var response *http.Response
+		if response, err = dispatcher.Client.Do(request); err == nil {
+			response.Body.Close()
+			delivery.StatusCode = response.StatusCode
+			delivery.Success = response.StatusCode >= 200 && response.StatusCode < 300
+			if !delivery.Success {
+				err = errors.New("Unexpected status " + response.Status)
+			}
+		}
+	}
+	if err != nil {
+		delivery.Error = err.Error()
+	}
+
+	if err := dispatcher.Store.InsertDelivery(&delivery); err != nil {
+		fmt.Println(err)
+	}
+	return delivery
+}
+
+/*
+	function WebhookDispatcher.Test()
+	@params:
+		webhook - webhook to test
+	@description:
+		post a webhook.test event once, without retries
+	@return:
+		Delivery - the logged attempt
+*/
+
+func (dispatcher *WebhookDispatcher) Test(webhook Webhook) Delivery {
+	event := Event{ID: primitive.NewObjectID(), Type: EventWebhookTest, CreatedAt: time.Now()}
+	return dispatcher.send(webhook, event, 1)
+}
+
+/*
+	function signWebhook()
+	@params:
+		secret - webhook secret
+		timestamp - X-Webhook-Timestamp of the delivery
+		body - posted body
+	@return:
+		string - hex HMAC-SHA256 of timestamp + "." + body
+*/
+
+func signWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}